@@ -0,0 +1,238 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/mitchellh/cli"
+)
+
+// App describes a cli tool and owns setting up and running it. It is the
+// supported entrypoint for cli tools built on cli-skeleton:
+//
+//	func main() {
+//		app := &command.App{
+//			Name:     "hello-world",
+//			Version:  Version,
+//			Commands: Commands,
+//		}
+//		os.Exit(app.Run(context.Background(), os.Args[1:]))
+//	}
+type App struct {
+	// Name is the name of the cli tool, as invoked by the user.
+	Name string
+
+	// Version is the version of the cli tool.
+	Version string
+
+	// Commands returns the mapping of subcommands implemented by the
+	// cli tool.
+	Commands CommandFunc
+
+	// HiddenCommands are the names of commands that are not listed in the
+	// help of the cli tool or in the docs generated by WriteDocs, but can
+	// still be run, such as DocsCommand.
+	HiddenCommands []string
+
+	// Categories orders the headings commands are grouped under in the
+	// help of the cli tool, by the category returned by their Category
+	// method. Categories that are not listed follow in sorted order.
+	Categories []string
+
+	// HelpTemplate replaces DefaultHelpTemplate for rendering the help of
+	// commands, for example to add sections to it.
+	HelpTemplate string
+
+	// Ui optionally wraps or replaces the default Ui, for example with
+	// ZerologUiWithFields or HumanZerologUiWithFields.
+	Ui func(ui cli.Ui) cli.Ui
+
+	// LegacyEnv exports the CLI_APP_NAME and CLI_VERSION env vars for
+	// commands that have not been migrated to Meta.AppName and
+	// Meta.AppVersion, along with NO_COLOR when --no-color is specified.
+	// These env vars are inherited by child processes, so this should
+	// only be enabled as a compatibility shim.
+	LegacyEnv bool
+
+	// AutoEnv binds every flag without an explicit env var to one derived
+	// from the names of the cli tool and the flag, for example
+	// HELLO_WORLD_COUNT for the --count flag of hello-world.
+	AutoEnv bool
+
+	// Config enables loading flag values from config files, as described
+	// by ConfigPaths and LoadConfig, and adds a --config flag to commands
+	// using FlagSetClient for specifying an additional config file. Flags
+	// are resolved in the order flag > env > config > default. A --profile
+	// flag is also added for selecting a named profile of the config.
+	Config bool
+
+	// Stdin, Stdout and Stderr replace the standard streams of the process
+	// for the Ui, the help output and anything else written by the cli
+	// tool, when not nil. Commands can access them via Meta.Stdin,
+	// Meta.Stdout and Meta.Stderr.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Getenv replaces os.Getenv for reading env vars, when not nil.
+	// Commands can read env vars via Meta.Getenv.
+	Getenv func(key string) string
+
+	// Dir replaces the current directory for finding config files, such
+	// as .hello-world.toml, resolving a relative --config path and
+	// checking that relative ArgumentPath and ArgumentFile values exist,
+	// when not empty.
+	Dir string
+
+	// Terminal overrides the detection of whether the standard streams
+	// are terminals, when not nil.
+	Terminal *Terminal
+
+	// DisableSignalHandler disables cancelling Meta.Context on SIGINT and
+	// SIGTERM, which is handled for the whole process and so should be
+	// disabled when running the cli tool in-process, such as in tests.
+	DisableSignalHandler bool
+
+	// ShutdownGracePeriod is the amount of time commands are given to
+	// return once a shutdown signal is received. If zero,
+	// DefaultShutdownGracePeriod is used. A negative value waits for the
+	// command to return or a second signal indefinitely.
+	ShutdownGracePeriod time.Duration
+
+	// BeforeRun is called once the Meta has been set up and before the
+	// subcommand is run. Returning an error aborts the run.
+	BeforeRun func(meta *Meta) error
+
+	// AfterRun is called once the subcommand has returned. The exit code
+	// it returns is used as the exit code of the cli tool.
+	AfterRun func(meta *Meta, exitCode int) int
+}
+
+// Run runs the subcommand specified by args, which should exclude the
+// name of the cli tool (for example os.Args[1:]), and returns the exit
+// code the process should exit with.
+func (a *App) Run(ctx context.Context, args []string) int {
+	meta := setupMeta(ctx, a, args)
+	defer meta.SignalHandler().Stop()
+
+	if a.LegacyEnv {
+		SetupEnv(args)
+		meta.ExportLegacyEnv()
+	}
+	meta.app = a
+	meta.autoEnv = a.AutoEnv
+	meta.hiddenCommands = a.HiddenCommands
+	meta.helpTemplate = a.HelpTemplate
+
+	// A config file that cannot be loaded or an invalid profile does not
+	// prevent showing help or running the commands that manage the config
+	// and profiles, such as to repair the config file, so their error is
+	// only reported once the subcommand is known
+	var configErr error
+	if a.Config {
+		meta.configFile = flagValueFromArgs(args, "config")
+		meta.dir = a.Dir
+		config, err := loadConfig(a.Name, meta.workingDir(), meta.configFile, meta.Getenv)
+		if err != nil {
+			configErr = err
+			config = newConfig()
+		} else {
+			warning, err := config.selectProfile(a.Name, args, meta.Getenv)
+			if warning != "" {
+				meta.Ui.Warn(warning)
+			}
+			configErr = err
+		}
+		meta.config = config
+		meta.profile = config.ActiveProfile()
+	}
+
+	if a.Ui != nil {
+		meta.Ui = a.Ui(meta.Ui)
+	}
+
+	if a.BeforeRun != nil {
+		if err := a.BeforeRun(meta); err != nil {
+			meta.Ui.Error(err.Error())
+			return 1
+		}
+	}
+
+	c := cli.NewCLI(a.Name, a.Version)
+	c.Args = args
+	c.HelpWriter = meta.Stderr()
+	c.ErrorWriter = meta.Stderr()
+	c.Commands = Commands(meta.Context, meta, a.Commands)
+	c.HiddenCommands = a.HiddenCommands
+
+	// Global flags may be specified before the subcommand
+	globalCommand := globalFlagCommand(c.Commands)
+	c.Args = reorderGlobalFlags(args, GlobalFlagSet(globalCommand))
+	c.AutocompleteGlobalFlags = AutocompleteGlobalFlagsFor(globalCommand)
+	c.HelpFunc = a.helpFunc(meta, c.Commands, globalCommand, hasAllFlag(args))
+
+	if configErr != nil {
+		if !allowsInvalidConfig(c, meta) {
+			meta.Ui.Error(configErr.Error())
+			return ExitCodeForError(configErr)
+		}
+		meta.Ui.Warn(configErr.Error())
+	}
+
+	exitCode, err := c.Run()
+	if err != nil {
+		meta.Ui.Error(fmt.Sprintf("Error executing CLI: %s", err.Error()))
+		exitCode = 1
+	}
+
+	exitCode = meta.SignalHandler().ExitCode(exitCode)
+	if a.AfterRun != nil {
+		exitCode = a.AfterRun(meta, exitCode)
+	}
+
+	return exitCode
+}
+
+// staticMeta returns the Meta of commands that are inspected rather than
+// run, such as by WriteDocs. Config files are not loaded, so the result
+// does not depend on the settings of the user.
+func (a *App) staticMeta() *Meta {
+	meta := &Meta{
+		appName:        a.Name,
+		version:        a.Version,
+		autoEnv:        a.AutoEnv,
+		hiddenCommands: a.HiddenCommands,
+	}
+	if a.Config {
+		meta.config = newConfig()
+	}
+	return meta
+}
+
+// allowsInvalidConfig returns whether the run of c may proceed without
+// config when a config file cannot be loaded or the selected profile does
+// not exist, which is the case for help, completions and the commands
+// managing config and profiles
+func allowsInvalidConfig(c *cli.CLI, meta *Meta) bool {
+	if c.IsHelp() || meta.Getenv("COMP_LINE") != "" {
+		return true
+	}
+
+	factory, ok := c.Commands[c.Subcommand()]
+	if !ok {
+		return true
+	}
+
+	cmd, err := factory()
+	if err != nil {
+		return false
+	}
+
+	switch cmd.(type) {
+	case *ConfigCommand, *ProfileCommand:
+		return true
+	}
+	return false
+}
@@ -3,8 +3,10 @@ package command
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Argument struct {
@@ -14,6 +16,21 @@ type Argument struct {
 	Type        ArgumentType
 	Value       interface{}
 	HasValue    bool
+
+	// Choices are the values accepted by an ArgumentEnum
+	Choices []string
+
+	// Default is the value of an optional argument that is not specified
+	Default interface{}
+
+	// Validate is called with the parsed value of the argument - or each
+	// value of an ArgumentList - and returns an error if it is invalid
+	Validate func(interface{}) error
+
+	// Env is the name of an env var used as the value of the argument when
+	// it is not specified on the command line. ArgumentList values are
+	// split on commas.
+	Env string
 }
 
 // ArgumentType is an enum to define what arguments are present
@@ -24,6 +41,22 @@ const (
 	ArgumentInt    ArgumentType = 1 << iota
 	ArgumentBool   ArgumentType = 2 << iota
 	ArgumentList   ArgumentType = 3 << iota
+
+	// ArgumentFloat is parsed as a float64
+	ArgumentFloat ArgumentType = 4 << iota
+
+	// ArgumentDuration is parsed as a time.Duration, e.g. "1m30s"
+	ArgumentDuration ArgumentType = 5 << iota
+
+	// ArgumentEnum is a string that must be one of the argument's Choices
+	ArgumentEnum ArgumentType = 6 << iota
+
+	// ArgumentPath is a path to a file or directory that must exist
+	ArgumentPath ArgumentType = 7 << iota
+
+	// ArgumentFile is a path to a file that must exist and must not
+	// be a directory
+	ArgumentFile ArgumentType = 8 << iota
 )
 
 func (a Argument) BoolValue() bool {
@@ -58,65 +91,88 @@ func (a Argument) ListValue() []string {
 	return a.Value.([]string)
 }
 
+func (a Argument) FloatValue() float64 {
+	if a.Type != ArgumentFloat {
+		panic(fmt.Errorf("Unexpected argument type for %s when calling FloatValue()", a.Name))
+	}
+
+	return a.Value.(float64)
+}
+
+func (a Argument) DurationValue() time.Duration {
+	if a.Type != ArgumentDuration {
+		panic(fmt.Errorf("Unexpected argument type for %s when calling DurationValue()", a.Name))
+	}
+
+	return a.Value.(time.Duration)
+}
+
+func (a Argument) EnumValue() string {
+	if a.Type != ArgumentEnum {
+		panic(fmt.Errorf("Unexpected argument type for %s when calling EnumValue()", a.Name))
+	}
+
+	return a.Value.(string)
+}
+
+func (a Argument) PathValue() string {
+	if a.Type != ArgumentPath {
+		panic(fmt.Errorf("Unexpected argument type for %s when calling PathValue()", a.Name))
+	}
+
+	return a.Value.(string)
+}
+
+func (a Argument) FileValue() string {
+	if a.Type != ArgumentFile {
+		panic(fmt.Errorf("Unexpected argument type for %s when calling FileValue()", a.Name))
+	}
+
+	return a.Value.(string)
+}
+
 func ArgumentAsString(arguments []Argument) string {
 	argumentString := []string{}
 
 	for _, argument := range arguments {
-		suffix := ""
-		if argument.Type == ArgumentList {
-			suffix = "..."
-		}
-
-		if argument.Optional {
-			argumentString = append(argumentString, fmt.Sprintf("[%s%s]", argument.Name, suffix))
-		} else {
-			argumentString = append(argumentString, fmt.Sprintf("<%s%s>", argument.Name, suffix))
-		}
+		argumentString = append(argumentString, argumentPlaceholder(argument))
 	}
 
 	return strings.Join(argumentString, " ")
 }
 
+// ArgumentsString renders arguments as shown in the help of a command,
+// without wrapping their descriptions.
 func ArgumentsString(arguments []Argument) string {
+	return ArgumentsStringWrapped(arguments, 0)
+}
+
+// ArgumentsStringWrapped renders arguments as shown in the help of a
+// command, wrapping their descriptions to cols columns. Pass cols == 0 to
+// do no wrapping.
+func ArgumentsStringWrapped(arguments []Argument, cols int) string {
 	maxlen := 0
 	lines := make([]string, 0, len(arguments))
 	for _, argument := range arguments {
-		line := ""
-
-		suffix := ""
-		if argument.Type == ArgumentList {
-			suffix = "..."
-		}
-
-		if argument.Optional {
-			line = fmt.Sprintf("      [%s%s]", argument.Name, suffix)
-		} else {
-			line = fmt.Sprintf("      <%s%s>", argument.Name, suffix)
-		}
-
-		switch argument.Type {
-		case ArgumentString:
-			line += " string"
-		case ArgumentInt:
-			line += " int"
-		case ArgumentBool:
-			line += " bool"
+		line := "      " + argumentPlaceholder(argument)
+		if typeName := argumentTypeName(argument); typeName != "" {
+			line += " " + typeName
 		}
 
+		// + 1 for the \x00, which is not printed
 		line += "\x00"
-		if len(line) > maxlen {
-			maxlen = len(line)
+		if width := displayWidth(line) + 1; width > maxlen {
+			maxlen = width
 		}
 
-		line += argument.Description
+		line += argumentUsage(argument)
 		lines = append(lines, line)
 	}
 
 	buf := new(bytes.Buffer)
-	cols := 0
 	for _, line := range lines {
 		sidx := strings.Index(line, "\x00")
-		spacing := strings.Repeat(" ", maxlen-sidx)
+		spacing := strings.Repeat(" ", maxlen-displayWidth(line[:sidx]))
 		// maxlen + 2 comes from + 1 for the \x00 and + 1 for the (deliberate) off-by-one in maxlen-sidx
 		fmt.Fprintln(buf, line[:sidx], spacing, wrap(maxlen+2, cols, line[sidx+1:]))
 	}
@@ -125,19 +181,20 @@ func ArgumentsString(arguments []Argument) string {
 }
 
 // Splits the string `s` on whitespace into an initial substring up to
-// `i` runes in length and the remainder. Will go `slop` over `i` if
+// `i` columns in length and the remainder. Will go `slop` over `i` if
 // that encompasses the entire string (which allows the caller to
 // avoid short orphan words on the final line).
 func wrapN(i, slop int, s string) (string, string) {
-	if i+slop > len(s) {
+	if i+slop > displayWidth(s) {
 		return s, ""
 	}
 
-	w := strings.LastIndexAny(s[:i], " \t\n")
+	end := widthIndex(s, i)
+	w := strings.LastIndexAny(s[:end], " \t\n")
 	if w <= 0 {
 		return s, ""
 	}
-	nlPos := strings.LastIndex(s[:i], "\n")
+	nlPos := strings.LastIndex(s[:end], "\n")
 	if nlPos > 0 && nlPos < w {
 		return s[:nlPos], s[nlPos+1:]
 	}
@@ -193,12 +250,33 @@ func wrap(i, w int, s string) string {
 
 }
 
+// ParseArguments parses args against the argument definitions in arguments,
+// returning the parsed arguments keyed by name. Arguments that are not
+// specified fall back to the value of their Env var, if set. The
+// definitions are never modified, so the same definitions may be parsed
+// repeatedly or from multiple goroutines at once. Invalid definitions,
+// such as a Default of the wrong type, are reported as an InternalError.
 func ParseArguments(args []string, arguments []Argument) (map[string]Argument, error) {
+	return parseArguments(args, arguments, ".", os.Getenv)
+}
+
+// ParseArguments parses args against the argument definitions in arguments
+// like the ParseArguments function, reading env vars via Getenv. Relative
+// paths are checked against App.Dir, if set.
+func (m *Meta) ParseArguments(args []string, arguments []Argument) (map[string]Argument, error) {
+	return parseArguments(args, arguments, m.workingDir(), m.Getenv)
+}
+
+// parseArguments implements ParseArguments, reading env vars with getenv
+// and checking relative paths against dir
+func parseArguments(args []string, arguments []Argument, dir string, getenv func(string) string) (map[string]Argument, error) {
 	returnArguments := map[string]Argument{}
 	if err := validateArguments(arguments); err != nil {
-		return returnArguments, err
+		return returnArguments, &InternalError{Err: err}
 	}
 
+	args = appendArgumentEnv(args, arguments, getenv)
+
 	maxArgs := len(arguments)
 	minArgs := 0
 	for _, argument := range arguments {
@@ -243,44 +321,56 @@ func ParseArguments(args []string, arguments []Argument) (map[string]Argument, e
 		return returnArguments, fmt.Errorf("%s, %d %s given: %s", errorMessage, len(args), argumentWord, ArgumentAsString(arguments))
 	}
 
+	// Parse into a copy of the definitions so that they are never modified
+	// and may be reused across calls
+	parsed := make([]Argument, len(arguments))
+	for i, argument := range arguments {
+		argument.Value = nil
+		argument.HasValue = false
+		parsed[i] = argument
+	}
+
 	hasListArgument := false
 	listIndex := 0
 	for i, value := range args {
 		if hasListArgument {
-			arguments[listIndex].HasValue = true
-			arguments[listIndex].Value = append(arguments[listIndex].Value.([]string), value)
+			if err := validateArgumentValue(parsed[listIndex], value); err != nil {
+				return returnArguments, err
+			}
+			parsed[listIndex].HasValue = true
+			parsed[listIndex].Value = append(parsed[listIndex].Value.([]string), value)
 		} else {
-			arguments[i].HasValue = true
-			if arguments[i].Type == ArgumentList {
+			parsed[i].HasValue = true
+			if parsed[i].Type == ArgumentList {
+				if err := validateArgumentValue(parsed[i], value); err != nil {
+					return returnArguments, err
+				}
 				hasListArgument = true
 				listIndex = i
-				arguments[i].Value = []string{value}
+				parsed[i].Value = []string{value}
 			} else {
-				if arguments[i].Type == ArgumentInt {
-					intValue, err := strconv.Atoi(value)
-					if err != nil {
-						return returnArguments, fmt.Errorf("Invalid value for argument %s", arguments[i].Name)
-					}
-					arguments[i].Value = intValue
-				} else {
-					arguments[i].Value = value
+				parsedValue, err := parseArgumentValue(parsed[i], value, dir)
+				if err != nil {
+					return returnArguments, err
 				}
+				if err := validateArgumentValue(parsed[i], parsedValue); err != nil {
+					return returnArguments, err
+				}
+				parsed[i].Value = parsedValue
 			}
 		}
 	}
 
-	for _, argument := range arguments {
-		if argument.Value == nil {
-			if argument.Type == ArgumentBool {
-				argument.Value = false
-			} else if argument.Type == ArgumentInt {
-				argument.Value = 0
-			} else if argument.Type == ArgumentList {
-				argument.Value = []string{}
-			} else if argument.Type == ArgumentString {
-				argument.Value = ""
+	for _, argument := range parsed {
+		if argument.Value == nil && argument.Default != nil {
+			argument.Value = argument.Default
+			if list, ok := argument.Default.([]string); ok {
+				argument.Value = append([]string{}, list...)
 			}
 			argument.HasValue = false
+		} else if argument.Value == nil {
+			argument.Value = zeroArgumentValue(argument.Type)
+			argument.HasValue = false
 		}
 		returnArguments[argument.Name] = argument
 	}
@@ -288,6 +378,185 @@ func ParseArguments(args []string, arguments []Argument) (map[string]Argument, e
 	return returnArguments, nil
 }
 
+// zeroArgumentValue returns the value of an argument of type t that is
+// not specified and has no default
+func zeroArgumentValue(t ArgumentType) interface{} {
+	switch t {
+	case ArgumentBool:
+		return false
+	case ArgumentInt:
+		return 0
+	case ArgumentFloat:
+		return float64(0)
+	case ArgumentDuration:
+		return time.Duration(0)
+	case ArgumentList:
+		return []string{}
+	case ArgumentString, ArgumentEnum, ArgumentPath, ArgumentFile:
+		return ""
+	}
+	return nil
+}
+
+// appendArgumentEnv appends the values of the env vars of the arguments
+// following those specified in args. Arguments are positional, so this
+// stops at the first argument without a value in the environment.
+func appendArgumentEnv(args []string, arguments []Argument, getenv func(string) string) []string {
+	if len(args) >= len(arguments) {
+		return args
+	}
+
+	withEnv := append([]string{}, args...)
+	for _, argument := range arguments[len(args):] {
+		if argument.Env == "" {
+			break
+		}
+
+		value := getenv(argument.Env)
+		if value == "" {
+			break
+		}
+
+		if argument.Type == ArgumentList {
+			withEnv = append(withEnv, strings.Split(value, ",")...)
+		} else {
+			withEnv = append(withEnv, value)
+		}
+	}
+
+	return withEnv
+}
+
+// validateArgumentValue runs the Validate function of argument against value
+func validateArgumentValue(argument Argument, value interface{}) error {
+	if argument.Validate == nil {
+		return nil
+	}
+
+	if err := argument.Validate(value); err != nil {
+		return fmt.Errorf("Invalid value for argument %s: %s", argument.Name, err.Error())
+	}
+	return nil
+}
+
+// argumentPlaceholder returns the placeholder for argument in usage
+// strings, such as <name> or [name...]
+func argumentPlaceholder(argument Argument) string {
+	suffix := ""
+	if argument.Type == ArgumentList {
+		suffix = "..."
+	}
+
+	if argument.Optional {
+		return fmt.Sprintf("[%s%s]", argument.Name, suffix)
+	}
+	return fmt.Sprintf("<%s%s>", argument.Name, suffix)
+}
+
+// argumentTypeName returns the name of the type of argument shown in help
+func argumentTypeName(argument Argument) string {
+	switch argument.Type {
+	case ArgumentString:
+		return "string"
+	case ArgumentInt:
+		return "int"
+	case ArgumentBool:
+		return "bool"
+	case ArgumentFloat:
+		return "float"
+	case ArgumentDuration:
+		return "duration"
+	case ArgumentEnum:
+		return strings.Join(argument.Choices, "|")
+	case ArgumentPath:
+		return "path"
+	case ArgumentFile:
+		return "file"
+	}
+	return ""
+}
+
+// argumentUsage returns the description of argument shown in help, along
+// with its env var and default value
+func argumentUsage(argument Argument) string {
+	usage := argument.Description
+	if argument.Env != "" {
+		usage += envUsage(argument.Env)
+	}
+	if argument.Default != nil {
+		usage += fmt.Sprintf(" (default %s)", formatArgumentDefault(argument.Default))
+	}
+	return usage
+}
+
+// formatArgumentDefault renders a default value the same way pflag renders
+// flag defaults
+func formatArgumentDefault(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case []string:
+		return "[" + strings.Join(v, ",") + "]"
+	}
+
+	return fmt.Sprintf("%v", value)
+}
+
+// parseArgumentValue converts a command line value to the type of argument.
+// Path and file values must exist, relative to dir if they are relative,
+// but are returned as specified.
+func parseArgumentValue(argument Argument, value string, dir string) (interface{}, error) {
+	switch argument.Type {
+	case ArgumentInt:
+		intValue, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value for argument %s", argument.Name)
+		}
+		return intValue, nil
+	case ArgumentBool:
+		boolValue, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value for argument %s", argument.Name)
+		}
+		return boolValue, nil
+	case ArgumentFloat:
+		floatValue, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value for argument %s", argument.Name)
+		}
+		return floatValue, nil
+	case ArgumentDuration:
+		durationValue, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value for argument %s", argument.Name)
+		}
+		return durationValue, nil
+	case ArgumentEnum:
+		for _, choice := range argument.Choices {
+			if value == choice {
+				return value, nil
+			}
+		}
+		return nil, fmt.Errorf("Invalid value for argument %s: must be one of %s", argument.Name, strings.Join(argument.Choices, ", "))
+	case ArgumentPath:
+		if _, err := os.Stat(resolvePath(dir, value)); err != nil {
+			return nil, fmt.Errorf("Invalid value for argument %s: %s does not exist", argument.Name, value)
+		}
+		return value, nil
+	case ArgumentFile:
+		info, err := os.Stat(resolvePath(dir, value))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value for argument %s: %s does not exist", argument.Name, value)
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("Invalid value for argument %s: %s is a directory", argument.Name, value)
+		}
+		return value, nil
+	}
+
+	return value, nil
+}
+
 func validateArguments(arguments []Argument) error {
 	reachedOptional := false
 	reachedList := false
@@ -307,6 +576,63 @@ func validateArguments(arguments []Argument) error {
 			listArgument = arg.Name
 			reachedList = true
 		}
+
+		if arg.Type == ArgumentEnum && len(arg.Choices) == 0 {
+			return fmt.Errorf("Enum Argument %s must specify one or more Choices", arg.Name)
+		}
+
+		if err := validateArgumentDefault(arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateArgumentDefault returns an error if the Default of argument is
+// not of the type of the argument, or is rejected by its Validate function
+func validateArgumentDefault(argument Argument) error {
+	if argument.Default == nil {
+		return nil
+	}
+
+	ok := false
+	switch argument.Type {
+	case ArgumentInt:
+		_, ok = argument.Default.(int)
+	case ArgumentBool:
+		_, ok = argument.Default.(bool)
+	case ArgumentList:
+		_, ok = argument.Default.([]string)
+	case ArgumentFloat:
+		_, ok = argument.Default.(float64)
+	case ArgumentDuration:
+		_, ok = argument.Default.(time.Duration)
+	case ArgumentString, ArgumentEnum, ArgumentPath, ArgumentFile:
+		_, ok = argument.Default.(string)
+	}
+	if !ok {
+		return fmt.Errorf("Default of Argument %s must be of type %T", argument.Name, zeroArgumentValue(argument.Type))
+	}
+
+	if argument.Type == ArgumentEnum && !containsString(argument.Choices, argument.Default.(string)) {
+		return fmt.Errorf("Default of Argument %s must be one of %s", argument.Name, strings.Join(argument.Choices, ", "))
+	}
+
+	if argument.Validate == nil {
+		return nil
+	}
+
+	values := []interface{}{argument.Default}
+	if list, ok := argument.Default.([]string); ok {
+		values = values[:0]
+		for _, value := range list {
+			values = append(values, value)
+		}
+	}
+	for _, value := range values {
+		if err := argument.Validate(value); err != nil {
+			return fmt.Errorf("Invalid default for argument %s: %s", argument.Name, err.Error())
+		}
 	}
 	return nil
 }
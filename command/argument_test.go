@@ -0,0 +1,165 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// testArguments returns argument definitions covering a required argument,
+// an optional argument with a default and a list argument with a default
+func testArguments() []Argument {
+	return []Argument{
+		{Name: "name", Type: ArgumentString},
+		{Name: "count", Type: ArgumentInt, Optional: true, Default: 1},
+		{Name: "tags", Type: ArgumentList, Optional: true, Default: []string{"a", "b"}},
+	}
+}
+
+func TestParseArgumentsRepeatedly(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		args  []string
+		count int
+		tags  []string
+	}{
+		{name: "defaults", args: []string{"lollipop"}, count: 1, tags: []string{"a", "b"}},
+		{name: "optional", args: []string{"lollipop", "3"}, count: 3, tags: []string{"a", "b"}},
+		{name: "list", args: []string{"lollipop", "3", "c", "d"}, count: 3, tags: []string{"c", "d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			arguments := testArguments()
+			for i := 0; i < 2; i++ {
+				parsed, err := ParseArguments(tt.args, arguments)
+				if err != nil {
+					t.Fatalf("parse %d: unexpected error: %s", i, err)
+				}
+
+				if got := parsed["name"].StringValue(); got != "lollipop" {
+					t.Errorf("parse %d: name = %q, want %q", i, got, "lollipop")
+				}
+				if got := parsed["count"].IntValue(); got != tt.count {
+					t.Errorf("parse %d: count = %d, want %d", i, got, tt.count)
+				}
+				if got := parsed["tags"].ListValue(); !reflect.DeepEqual(got, tt.tags) {
+					t.Errorf("parse %d: tags = %v, want %v", i, got, tt.tags)
+				}
+
+				// Modifying the parsed list must not modify the default
+				parsed["tags"].ListValue()[0] = "modified"
+			}
+
+			if !reflect.DeepEqual(arguments, testArguments()) {
+				t.Errorf("definitions were modified: %#v", arguments)
+			}
+		})
+	}
+}
+
+func TestParseArgumentsConcurrently(t *testing.T) {
+	t.Parallel()
+
+	arguments := testArguments()
+	argsList := [][]string{
+		{"lollipop"},
+		{"lollipop", "3"},
+		{"lollipop", "3", "c", "d"},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(args []string) {
+			defer wg.Done()
+			if _, err := ParseArguments(args, arguments); err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		}(argsList[i%len(argsList)])
+	}
+	wg.Wait()
+
+	for _, argument := range arguments {
+		if argument.Value != nil || argument.HasValue {
+			t.Errorf("definition of %s was modified: Value = %v, HasValue = %v", argument.Name, argument.Value, argument.HasValue)
+		}
+	}
+	if !reflect.DeepEqual(arguments, testArguments()) {
+		t.Errorf("definitions were modified: %#v", arguments)
+	}
+}
+
+func TestParseArgumentsInvalidDefault(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		argument Argument
+	}{
+		{name: "mistyped", argument: Argument{Name: "count", Type: ArgumentInt, Optional: true, Default: "3"}},
+		{name: "invalid", argument: Argument{Name: "count", Type: ArgumentInt, Optional: true, Default: 3, Validate: ValidateIntRange(5, 9)}},
+		{name: "enum", argument: Argument{Name: "color", Type: ArgumentEnum, Choices: []string{"red"}, Optional: true, Default: "blue"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParseArguments(nil, []Argument{tt.argument})
+			if err == nil {
+				t.Fatalf("expected an error for default %v", tt.argument.Default)
+			}
+			if code := ExitCodeForError(err); code != ExitCodeInternal {
+				t.Errorf("exit code = %d, want %d", code, ExitCodeInternal)
+			}
+		})
+	}
+}
+
+func TestParseArgumentsRelativePath(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "jar"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "jar", "lollipop.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		argument Argument
+		value    string
+	}{
+		{name: "path", argument: Argument{Name: "jar", Type: ArgumentPath}, value: "jar"},
+		{name: "file", argument: Argument{Name: "lollipop", Type: ArgumentFile}, value: filepath.Join("jar", "lollipop.txt")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			meta := &Meta{dir: dir}
+			parsed, err := meta.ParseArguments([]string{tt.value}, []Argument{tt.argument})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got := parsed[tt.argument.Name].Value; got != tt.value {
+				t.Errorf("value = %v, want %q as specified", got, tt.value)
+			}
+
+			// The current directory is not consulted
+			if _, err := ParseArguments([]string{tt.value}, []Argument{tt.argument}); err == nil {
+				t.Errorf("expected %s to not exist in the current directory", tt.value)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/cli"
+)
+
+// CategorizedCommand is implemented by commands that are listed under a
+// heading of their category in the help of the cli tool, such as
+// "Lollipop commands". Commands without a category are listed under
+// "Other commands", unless no command has a category, in which case every
+// command is listed together as in the default help of mitchellh/cli.
+type CategorizedCommand interface {
+	Category() string
+}
+
+// OrderedCommand is implemented by commands that are listed in a given
+// order within their category in the help of the cli tool. Commands are
+// listed in ascending order, with commands of the same order, or that do
+// not implement OrderedCommand and so have order 0, sorted by name.
+type OrderedCommand interface {
+	Order() int
+}
+
+// DeprecatedCommand is implemented by commands that are deprecated. A
+// command is deprecated if Deprecated returns a non-empty message, such as
+// the command to use instead. Deprecated commands can still be run, but
+// are only listed in the help of the cli tool when requested with --all,
+// and RunCommand warns about their use.
+type DeprecatedCommand interface {
+	Deprecated() string
+}
+
+const (
+	// categoryAvailable is the heading of commands when no command has a
+	// category
+	categoryAvailable = "Available commands are"
+
+	// categoryOther is the heading of commands without a category
+	categoryOther = "Other commands"
+
+	// categoryDeprecated is the heading of deprecated commands
+	categoryDeprecated = "Deprecated commands"
+
+	// categoryHidden is the heading of hidden commands
+	categoryHidden = "Hidden commands"
+)
+
+// commandGroup is a heading of the help of the cli tool, along with the
+// commands listed under it
+type commandGroup struct {
+	heading  string
+	commands []listedCommand
+}
+
+// listedCommand is a command listed in the help of the cli tool
+type listedCommand struct {
+	name     string
+	synopsis string
+	order    int
+}
+
+// deprecationMessage returns the deprecation message of c, or an empty
+// string if it is not deprecated
+func deprecationMessage(c interface{}) string {
+	if d, ok := c.(DeprecatedCommand); ok {
+		return d.Deprecated()
+	}
+	return ""
+}
+
+// helpFunc returns the function rendering the help of the cli tool, which
+// lists commands grouped by category, followed by the global flags of the
+// cli tool. Hidden and deprecated commands are listed separately when all
+// is set. The commands passed to the function are those at the level the
+// help is rendered for, without hidden commands, so hidden commands are
+// looked up in factories.
+func (a *App) helpFunc(meta *Meta, factories map[string]cli.CommandFactory, globalCommand cli.Command, all bool) cli.HelpFunc {
+	return func(commands map[string]cli.CommandFactory) string {
+		prefix := ""
+		for name := range commands {
+			prefix = commandParent(name)
+			break
+		}
+
+		groups, deprecated := groupCommands(commands, a.Categories)
+		omitted := len(deprecated.commands)
+
+		hidden := commandGroup{heading: categoryHidden}
+		for _, name := range a.HiddenCommands {
+			factory, ok := factories[name]
+			if !ok || commandParent(name) != prefix {
+				continue
+			}
+			if c, err := factory(); err == nil {
+				hidden.commands = append(hidden.commands, listedCommand{name: name, synopsis: c.Synopsis()})
+			}
+		}
+		sortCommands(hidden.commands)
+		omitted += len(hidden.commands)
+
+		if all {
+			groups = append(groups, deprecated, hidden)
+		}
+
+		width := meta.HelpWidth()
+		buf := new(bytes.Buffer)
+		fmt.Fprintf(buf, "Usage: %s [--version] [--help] <command> [<args>]\n", a.Name)
+		writeCommandGroups(buf, groups, width)
+
+		if globalFlags := globalFlagUsages(globalCommand, width); globalFlags != "" {
+			fmt.Fprintf(buf, "\nGlobal Options:\n\n%s", globalFlags)
+		}
+
+		if !all && omitted > 0 {
+			fmt.Fprintf(buf, "\nRun %s --help --all to also list hidden and deprecated commands.\n", a.Name)
+		}
+		return strings.TrimRight(buf.String(), "\n")
+	}
+}
+
+// groupCommands groups commands by category, returning the groups in the
+// order of categories, followed by any other categories in sorted order
+// and the commands without a category. Deprecated commands are returned
+// in a group of their own.
+func groupCommands(commands map[string]cli.CommandFactory, categories []string) ([]commandGroup, commandGroup) {
+	deprecated := commandGroup{heading: categoryDeprecated}
+	byCategory := map[string][]listedCommand{}
+	for name, factory := range commands {
+		c, err := factory()
+		if err != nil {
+			continue
+		}
+
+		listed := listedCommand{name: name, synopsis: c.Synopsis()}
+		if o, ok := c.(OrderedCommand); ok {
+			listed.order = o.Order()
+		}
+
+		if message := deprecationMessage(c); message != "" {
+			listed.synopsis += " (deprecated: " + message + ")"
+			deprecated.commands = append(deprecated.commands, listed)
+			continue
+		}
+
+		category := ""
+		if cc, ok := c.(CategorizedCommand); ok {
+			category = cc.Category()
+		}
+		byCategory[category] = append(byCategory[category], listed)
+	}
+	sortCommands(deprecated.commands)
+
+	if len(byCategory) == 1 && byCategory[""] != nil {
+		sortCommands(byCategory[""])
+		return []commandGroup{{heading: categoryAvailable, commands: byCategory[""]}}, deprecated
+	}
+
+	others := []string{}
+	for category := range byCategory {
+		if category != "" && !containsString(categories, category) {
+			others = append(others, category)
+		}
+	}
+	sort.Strings(others)
+
+	groups := []commandGroup{}
+	for _, category := range append(append(append([]string{}, categories...), others...), "") {
+		listed, ok := byCategory[category]
+		if !ok {
+			continue
+		}
+
+		heading := category
+		if heading == "" {
+			heading = categoryOther
+		}
+		sortCommands(listed)
+		groups = append(groups, commandGroup{heading: heading, commands: listed})
+	}
+	return groups, deprecated
+}
+
+// sortCommands sorts commands by order and then by name
+func sortCommands(commands []listedCommand) {
+	sort.SliceStable(commands, func(i, j int) bool {
+		if commands[i].order != commands[j].order {
+			return commands[i].order < commands[j].order
+		}
+		return commands[i].name < commands[j].name
+	})
+}
+
+// writeCommandGroups writes groups that are not empty to buf, with the
+// synopses of the commands of every group aligned and wrapped to width
+func writeCommandGroups(buf *bytes.Buffer, groups []commandGroup, width int) {
+	maxlen := 0
+	for _, group := range groups {
+		for _, c := range group.commands {
+			if w := displayWidth(c.name); w > maxlen {
+				maxlen = w
+			}
+		}
+	}
+
+	for _, group := range groups {
+		if len(group.commands) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(buf, "\n%s:\n", group.heading)
+		for _, c := range group.commands {
+			spacing := strings.Repeat(" ", maxlen-displayWidth(c.name))
+			fmt.Fprintf(buf, "    %s%s    %s\n", c.name, spacing, wrap(maxlen+8, width, c.synopsis))
+		}
+	}
+}
+
+// globalFlagUsages returns the usage of the global flags of cmd, wrapped
+// to width, or an empty string if it has none
+func globalFlagUsages(cmd cli.Command, width int) string {
+	c, ok := cmd.(Command)
+	if !ok {
+		return ""
+	}
+
+	_, globalFlags := commandFlagSets(c)
+	if globalFlags == nil {
+		return ""
+	}
+	return globalFlags.FlagUsagesWrapped(width)
+}
+
+// commandParent returns the name of the parent of the nested command
+// name, or an empty string if it is not nested
+func commandParent(name string) string {
+	if i := strings.LastIndex(name, " "); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// hasAllFlag returns whether args request listing every command with
+// --all, before any -- separator
+func hasAllFlag(args []string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == "-all" || arg == "--all" {
+			return true
+		}
+	}
+	return false
+}
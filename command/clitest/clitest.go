@@ -0,0 +1,166 @@
+// Package clitest runs cli tools built on cli-skeleton in-process for
+// tests, capturing their output and exit code. Each run is isolated from
+// the environment, standard streams, terminal and signals of the test
+// process, so tests may run in parallel:
+//
+//	func TestEat(t *testing.T) {
+//		t.Parallel()
+//
+//		h := &clitest.Harness{
+//			App: command.App{Name: "hello-world", Commands: Commands},
+//			Env: map[string]string{"LOLLIPOP_COLOR": "red"},
+//		}
+//		result := h.Run(t, "eat", "--count", "2")
+//		if result.ExitCode != 0 {
+//			t.Fatalf("unexpected exit code %d: %s", result.ExitCode, result.Stderr)
+//		}
+//	}
+package clitest
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/josegonzalez/cli-skeleton/command"
+)
+
+// Harness runs an App in-process. The zero values of its fields describe
+// a non-interactive run with an empty environment and no input.
+type Harness struct {
+	// App is the cli tool to run. Its standard streams, environment and
+	// terminal are replaced by those of the Harness on every run, signal
+	// handling is disabled, and LegacyEnv is ignored as it modifies the
+	// environment of the test process. Unless App.Dir is set, config
+	// files are looked up in the temporary directory of the test rather
+	// than the current directory.
+	App command.App
+
+	// Env holds the env vars visible to the cli tool, which does not
+	// inherit the environment of the test process. Unless set, HOME,
+	// XDG_CONFIG_HOME and XDG_CONFIG_DIRS point to a temporary directory
+	// so that config files of the user running the tests are not loaded.
+	// The directory is shared by every run within the same test, so
+	// config written by one run is read by the next.
+	Env map[string]string
+
+	// Stdin is the input available to the cli tool, such as the answers
+	// to prompts separated by newlines
+	Stdin string
+
+	// Terminal sets which of the standard streams are treated as
+	// terminals, and the width of the terminal. None are by default.
+	Terminal command.Terminal
+
+	mu   sync.Mutex
+	dirs map[testing.TB]string
+}
+
+// Result holds the output and exit code of a run
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+
+	// dir is the temporary directory of the run
+	dir string
+}
+
+// Run runs the cli tool with args, which should exclude the name of the
+// cli tool, and returns its output and exit code. The context of the run
+// is cancelled when the test finishes.
+func (h *Harness) Run(t testing.TB, args ...string) *Result {
+	t.Helper()
+
+	stdout := &syncBuffer{}
+	stderr := &syncBuffer{}
+	terminal := h.Terminal
+
+	app := h.App
+	app.Stdin = strings.NewReader(h.Stdin)
+	app.Stdout = stdout
+	app.Stderr = stderr
+	app.Getenv = h.getenv(t)
+	app.Terminal = &terminal
+	app.DisableSignalHandler = true
+	app.LegacyEnv = false
+	if app.Dir == "" {
+		app.Dir = h.tempDir(t)
+	}
+
+	exitCode := app.Run(t.Context(), args)
+	return &Result{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		ExitCode: exitCode,
+		dir:      h.tempDir(t),
+	}
+}
+
+// getenv returns a function reading env vars from Env, pointing any config
+// directories that are not set to a temporary directory
+func (h *Harness) getenv(t testing.TB) func(string) string {
+	env := map[string]string{}
+	for key, value := range h.Env {
+		env[key] = value
+	}
+
+	dir := h.tempDir(t)
+	defaults := map[string]string{
+		"HOME":            dir,
+		"XDG_CONFIG_HOME": filepath.Join(dir, ".config"),
+		"XDG_CONFIG_DIRS": filepath.Join(dir, "xdg"),
+	}
+	for key, value := range defaults {
+		if _, ok := env[key]; !ok {
+			env[key] = value
+		}
+	}
+
+	return func(key string) string {
+		return env[key]
+	}
+}
+
+// tempDir returns the temporary directory of the test t
+func (h *Harness) tempDir(t testing.TB) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if dir, ok := h.dirs[t]; ok {
+		return dir
+	}
+
+	if h.dirs == nil {
+		h.dirs = map[testing.TB]string{}
+	}
+	dir := t.TempDir()
+	h.dirs[t] = dir
+	t.Cleanup(func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.dirs, t)
+	})
+	return dir
+}
+
+// syncBuffer is a bytes.Buffer that may be written to by multiple
+// goroutines, such as the Ui and a progress bar
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,207 @@
+package clitest
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/josegonzalez/cli-skeleton/command"
+	"github.com/mitchellh/cli"
+)
+
+// testCommand is a command running a function with its Meta
+type testCommand struct {
+	meta command.Meta
+	run  func(meta *command.Meta, args []string) int
+}
+
+func (c *testCommand) Help() string {
+	return "Usage: test"
+}
+
+func (c *testCommand) Synopsis() string {
+	return "A test command"
+}
+
+func (c *testCommand) Run(args []string) int {
+	return c.run(&c.meta, args)
+}
+
+// testCommands returns commands that write their arguments to stdout and
+// stderr, print an env var or a line of stdin and exit with a given code
+func testCommands(ctx context.Context, meta command.Meta) map[string]cli.CommandFactory {
+	commands := map[string]func(meta *command.Meta, args []string) int{
+		"echo": func(meta *command.Meta, args []string) int {
+			fmt.Fprintln(meta.Stdout(), strings.Join(args, " "))
+			return 0
+		},
+		"warn": func(meta *command.Meta, args []string) int {
+			fmt.Fprintln(meta.Stderr(), strings.Join(args, " "))
+			return 0
+		},
+		"env": func(meta *command.Meta, args []string) int {
+			fmt.Fprintln(meta.Stdout(), meta.Getenv(args[0]))
+			return 0
+		},
+		"read": func(meta *command.Meta, args []string) int {
+			line, err := bufio.NewReader(meta.Stdin()).ReadString('\n')
+			if err != nil {
+				fmt.Fprintln(meta.Stderr(), err)
+				return 1
+			}
+			fmt.Fprint(meta.Stdout(), line)
+			return 0
+		},
+		"exit": func(meta *command.Meta, args []string) int {
+			code, err := strconv.Atoi(args[0])
+			if err != nil {
+				return 1
+			}
+			return code
+		},
+	}
+
+	factories := map[string]cli.CommandFactory{}
+	for name, run := range commands {
+		factories[name] = func() (cli.Command, error) {
+			return &testCommand{meta: meta, run: run}, nil
+		}
+	}
+	return factories
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		args     []string
+		env      map[string]string
+		stdin    string
+		stdout   string
+		stderr   string
+		exitCode int
+	}{
+		{name: "stdout", args: []string{"echo", "hello", "world"}, stdout: "hello world\n"},
+		{name: "stderr", args: []string{"warn", "careful"}, stderr: "careful\n"},
+		{name: "exit code", args: []string{"exit", "3"}, exitCode: 3},
+		{name: "env", args: []string{"env", "COLOR"}, env: map[string]string{"COLOR": "red"}, stdout: "red\n"},
+		{name: "unset env", args: []string{"env", "PATH"}, stdout: "\n"},
+		{name: "stdin", args: []string{"read"}, stdin: "lollipop\n", stdout: "lollipop\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := &Harness{
+				App:   command.App{Name: "test", Commands: testCommands},
+				Env:   tt.env,
+				Stdin: tt.stdin,
+			}
+			result := h.Run(t, tt.args...)
+			if result.Stdout != tt.stdout {
+				t.Errorf("stdout = %q, want %q", result.Stdout, tt.stdout)
+			}
+			if result.Stderr != tt.stderr {
+				t.Errorf("stderr = %q, want %q", result.Stderr, tt.stderr)
+			}
+			if result.ExitCode != tt.exitCode {
+				t.Errorf("exit code = %d, want %d", result.ExitCode, tt.exitCode)
+			}
+		})
+	}
+}
+
+func TestRunParallel(t *testing.T) {
+	t.Parallel()
+
+	app := command.App{Name: "test", Commands: testCommands}
+	for i := 0; i < 10; i++ {
+		color := strconv.Itoa(i)
+		t.Run(color, func(t *testing.T) {
+			t.Parallel()
+
+			h := &Harness{
+				App: app,
+				Env: map[string]string{"COLOR": color},
+			}
+			result := h.Run(t, "env", "COLOR")
+			if want := color + "\n"; result.Stdout != want {
+				t.Errorf("stdout = %q, want %q", result.Stdout, want)
+			}
+		})
+	}
+}
+
+func TestRunConfigDirs(t *testing.T) {
+	t.Parallel()
+
+	h := &Harness{App: command.App{Name: "test", Commands: testCommands}}
+	home := strings.TrimSuffix(h.Run(t, "env", "HOME").Stdout, "\n")
+	if home != h.tempDir(t) {
+		t.Errorf("HOME = %q, want the temporary directory %q", home, h.tempDir(t))
+	}
+
+	// Config directories are shared by every run of the same test
+	for _, key := range []string{"XDG_CONFIG_HOME", "XDG_CONFIG_DIRS"} {
+		dir := h.Run(t, "env", key).Stdout
+		if !strings.HasPrefix(dir, home) {
+			t.Errorf("%s = %q, want a directory within %q", key, dir, home)
+		}
+	}
+
+	// Env vars that are set are not replaced
+	h.Env = map[string]string{"HOME": "/home/lollipop"}
+	if result := h.Run(t, "env", "HOME"); result.Stdout != "/home/lollipop\n" {
+		t.Errorf("HOME = %q, want %q", result.Stdout, "/home/lollipop\n")
+	}
+}
+
+func TestSyncBuffer(t *testing.T) {
+	t.Parallel()
+
+	buf := &syncBuffer{}
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fmt.Fprint(buf, "a")
+		}()
+	}
+	wg.Wait()
+
+	if got := buf.String(); got != strings.Repeat("a", 50) {
+		t.Errorf("buffer = %q, want 50 writes", got)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "ansi", input: "\x1b[31mred\x1b[0m", want: "red"},
+		{name: "timestamp", input: "at 2024-01-02T03:04:05Z", want: "at <TIMESTAMP>"},
+		{name: "time", input: "at 3:04PM", want: "at <TIME>"},
+		{name: "line endings", input: "a\r\nb\r\n", want: "a\nb\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := Normalize(tt.input); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
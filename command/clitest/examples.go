@@ -0,0 +1,137 @@
+package clitest
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+// RunExamples runs the examples of the named commands, or of every command
+// that is not hidden if no names are given, each as a subtest of t named
+// after the command and the description of the example. An example fails
+// if it exits with a non-zero exit code, or if it has an expected Output
+// that differs from its stdout. Both are compared with surrounding
+// whitespace removed, after normalising the stdout as described by
+// Normalize and replacing the temporary directory of the run with
+// <TMPDIR>.
+//
+// Examples are skipped if they run a different program than the cli tool,
+// or rely on the shell with pipes, redirections, variables or command
+// substitutions, as they cannot be run in-process. Each example runs with
+// its own temporary directory, so commands with examples that depend on
+// existing state or open an editor, such as ConfigCommand, should be left
+// out by naming the commands to run.
+func (h *Harness) RunExamples(t *testing.T, names ...string) {
+	t.Helper()
+
+	examples := h.App.Examples()
+	if len(names) == 0 {
+		for name := range examples {
+			if !contains(h.App.HiddenCommands, name) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+	}
+
+	for _, name := range names {
+		if _, ok := examples[name]; !ok {
+			t.Errorf("Unable to run examples of %s: no such command", name)
+			continue
+		}
+
+		t.Run(name, func(t *testing.T) {
+			for _, example := range examples[name] {
+				t.Run(example.Description, func(t *testing.T) {
+					args, ok := splitCommandLine(example.Command)
+					if !ok || len(args) == 0 || args[0] != h.App.Name {
+						t.Skipf("Unable to run %q in-process", example.Command)
+					}
+
+					result := h.Run(t, args[1:]...)
+					if result.ExitCode != 0 {
+						t.Fatalf("Example exited with exit code %d\n$ %s\n%s%s", result.ExitCode, example.Command, result.Stdout, result.Stderr)
+					}
+
+					if example.Output == "" {
+						return
+					}
+
+					actual := strings.ReplaceAll(Normalize(result.Stdout), result.dir, "<TMPDIR>")
+					if strings.TrimSpace(actual) != strings.TrimSpace(example.Output) {
+						t.Errorf("Output of example does not match\n$ %s\n--- expected\n%s\n--- actual\n%s", example.Command, strings.TrimSpace(example.Output), strings.TrimSpace(actual))
+					}
+				})
+			}
+		})
+	}
+}
+
+// splitCommandLine splits s into arguments as a shell would, honouring
+// single and double quotes and backslash escapes, and returns false if s
+// uses any other shell syntax or has an unterminated quote
+func splitCommandLine(s string) ([]string, bool) {
+	args := []string{}
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '$', '`':
+				return nil, false
+			default:
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		case strings.ContainsRune("|&;<>()$`*?[]{}~#\n", r):
+			return nil, false
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, false
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, true
+}
+
+// contains returns whether values contains value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
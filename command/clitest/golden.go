@@ -0,0 +1,146 @@
+package clitest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var (
+	// ansiPattern matches ANSI escape sequences, such as colors
+	ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+	// timestampPattern matches RFC 3339 timestamps, as logged by zerolog
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`)
+
+	// timePattern matches times in the time.Kitchen format, as logged by
+	// HumanWriter and the zerolog ConsoleWriter
+	timePattern = regexp.MustCompile(`\b\d{1,2}:\d{2}(AM|PM)\b`)
+)
+
+// AssertGolden compares the stdout, stderr and exit code of the run with
+// the golden file testdata/<name>.golden, failing t if they differ. The
+// output is normalised first, as described by Normalize, with the
+// temporary directory of the run replaced by <TMPDIR>. The golden file is
+// rewritten instead if requested, as described by AssertGolden.
+func (r *Result) AssertGolden(t testing.TB, name string) {
+	t.Helper()
+
+	actual := fmt.Sprintf("exit code: %d\n-- stdout --\n%s-- stderr --\n%s", r.ExitCode, withNewline(r.Stdout), withNewline(r.Stderr))
+	if r.dir != "" {
+		actual = strings.ReplaceAll(actual, r.dir, "<TMPDIR>")
+	}
+	AssertGolden(t, name, actual)
+}
+
+// AssertGolden compares actual with the golden file testdata/<name>.golden,
+// failing t if they differ. It can be used directly for output that is not
+// produced by a run, such as the result of CommandHelp. Name may contain
+// slashes, as in t.Name() for subtests, to place the golden file in a
+// subdirectory. Actual is normalised first, as described by Normalize.
+//
+// The golden file is rewritten with actual instead if go test is run with
+// -clitest.update, as in go test ./... -clitest.update, or with the
+// UPDATE_GOLDEN env var set to a true value. The -update flag is honoured
+// too, but only in test packages that declare it themselves.
+func AssertGolden(t testing.TB, name string, actual string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", filepath.FromSlash(name)+".golden")
+	actual = Normalize(actual)
+
+	if updateGolden() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Unable to create golden file directory: %s", err)
+		}
+		if err := os.WriteFile(path, []byte(actual), 0o644); err != nil {
+			t.Fatalf("Unable to write golden file: %s", err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unable to read golden file, run go test with -clitest.update to create it: %s", err)
+	}
+
+	if line, ok := firstDifference(string(expected), actual); ok {
+		t.Errorf("Output does not match golden file %s at line %d, run go test with -clitest.update to accept the changes\n--- expected\n%s\n--- actual\n%s", path, line, expected, actual)
+	}
+}
+
+// update is the -clitest.update flag, which is namespaced so that it does
+// not conflict with an -update flag declared by the test package
+var update = flag.Bool("clitest.update", false, "rewrite golden files with the actual output")
+
+// updateGolden returns whether golden files should be rewritten with the
+// actual output
+func updateGolden() bool {
+	if *update {
+		return true
+	}
+
+	if update, err := strconv.ParseBool(os.Getenv("UPDATE_GOLDEN")); err == nil {
+		return update
+	}
+
+	if f := flag.Lookup("update"); f != nil {
+		if getter, ok := f.Value.(flag.Getter); ok {
+			update, _ := getter.Get().(bool)
+			return update
+		}
+	}
+	return false
+}
+
+// Normalize replaces the parts of s that vary between runs, so that it can
+// be compared with a golden file:
+//
+//   - ANSI escape sequences are removed
+//   - RFC 3339 timestamps are replaced by <TIMESTAMP>
+//   - times in the time.Kitchen format are replaced by <TIME>
+//   - the working directory is replaced by <CWD>
+//   - carriage return and line feed pairs are replaced by line feeds
+func Normalize(s string) string {
+	s = ansiPattern.ReplaceAllString(s, "")
+	s = timestampPattern.ReplaceAllString(s, "<TIMESTAMP>")
+	s = timePattern.ReplaceAllString(s, "<TIME>")
+	if cwd, err := os.Getwd(); err == nil {
+		s = strings.ReplaceAll(s, cwd, "<CWD>")
+	}
+	return strings.ReplaceAll(s, "\r\n", "\n")
+}
+
+// firstDifference returns the first line number at which expected and
+// actual differ, and whether they differ at all
+func firstDifference(expected string, actual string) (int, bool) {
+	if expected == actual {
+		return 0, false
+	}
+
+	expectedLines := strings.Split(expected, "\n")
+	actualLines := strings.Split(actual, "\n")
+	for i := 0; i < len(expectedLines) && i < len(actualLines); i++ {
+		if expectedLines[i] != actualLines[i] {
+			return i + 1, true
+		}
+	}
+
+	if len(expectedLines) < len(actualLines) {
+		return len(expectedLines) + 1, true
+	}
+	return len(actualLines) + 1, true
+}
+
+// withNewline returns s ending in a newline, unless it is empty
+func withNewline(s string) string {
+	if s == "" || strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return s + "\n"
+}
@@ -2,10 +2,8 @@ package command
 
 import (
 	"context"
-	"os"
-	"strings"
+	"fmt"
 
-	colorable "github.com/mattn/go-colorable"
 	"github.com/mitchellh/cli"
 	flag "github.com/spf13/pflag"
 )
@@ -13,6 +11,14 @@ import (
 const (
 	// EnvCLINoColor is an env var that toggles colored UI output.
 	EnvCLINoColor = `NO_COLOR`
+
+	// EnvCLIAppName is an env var containing the name of the cli tool.
+	// It is only set when App.LegacyEnv is enabled.
+	EnvCLIAppName = `CLI_APP_NAME`
+
+	// EnvCLIVersion is an env var containing the version of the cli tool.
+	// It is only set when App.LegacyEnv is enabled.
+	EnvCLIVersion = `CLI_VERSION`
 )
 
 // NamedCommand is a interface to denote a commmand's name.
@@ -31,15 +37,22 @@ func Commands(ctx context.Context, metaPtr *Meta, commandsFunc CommandFunc) map[
 
 	meta := *metaPtr
 	if meta.Ui == nil {
-		meta.Ui = &cli.BasicUi{
-			Reader:      os.Stdin,
-			Writer:      colorable.NewColorableStdout(),
-			ErrorWriter: colorable.NewColorableStderr(),
-		}
+		meta.Ui = meta.basicUi()
 	}
 	meta.Context = ctx
 
-	return commandsFunc(ctx, meta)
+	// Every command shares the registry, which lets commands such as
+	// ConfigCommand inspect the other commands of the cli tool
+	meta.commands = &commandRegistry{}
+	factories := commandsFunc(ctx, meta)
+	meta.commands.factories = factories
+
+	return factories
+}
+
+// commandRegistry holds the commands of the cli tool
+type commandRegistry struct {
+	factories map[string]cli.CommandFactory
 }
 
 type Command interface {
@@ -50,37 +63,46 @@ type Command interface {
 	Examples() map[string]string
 }
 
+// CommandHelp renders the help of c with its help template, which is
+// DefaultHelpTemplate unless c has a CommandHelpTemplate method returning
+// another, as commands embedding Meta do.
 func CommandHelp(c Command) string {
-	appName := os.Getenv("CLI_APP_NAME")
-	helpText := `
-Usage: ` + appName + ` ` + c.Name() + ` ` + FlagString(c.FlagSet()) + ` ` + ArgumentAsString(c.Arguments()) + `
-
-  ` + c.Synopsis()
-
-	options := c.FlagSet().FlagUsages()
-	if options != "" {
-		helpText += `
-
-Options:
-
-` + options
+	text := DefaultHelpTemplate
+	if t, ok := c.(interface{ CommandHelpTemplate() string }); ok {
+		text = t.CommandHelpTemplate()
 	}
 
-	arguments := ArgumentsString(c.Arguments())
-	if arguments != "" {
-		helpText += `
-Arguments:
-
-` + arguments
+	help, err := renderHelp(text, newHelpData(c))
+	if err != nil {
+		return fmt.Sprintf("Unable to render the help of %s: %s\n", c.Name(), err.Error())
 	}
+	return help
+}
 
-	examples := ExampleString(c.Examples())
-	if examples != "" {
-		helpText += `
-Examples:
-
-` + examples
+// commandFlagSets returns the flags of c, bound to their env vars, split
+// into the command's own flags and the global flags, which are nil if c
+// has none.
+func commandFlagSets(c Command) (*flag.FlagSet, *flag.FlagSet) {
+	globalFlags := GlobalFlagSet(c)
+	commandFlags := c.FlagSet()
+	if b, ok := c.(interface{ BindFlagEnv(*flag.FlagSet) }); ok {
+		b.BindFlagEnv(commandFlags)
+		if globalFlags != nil {
+			b.BindFlagEnv(globalFlags)
+		}
 	}
 
-	return strings.TrimSpace(helpText) + "\n"
+	flags := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	commandFlags.VisitAll(func(f *flag.Flag) {
+		if globalFlags == nil || globalFlags.Lookup(f.Name) == nil {
+			flags.AddFlag(f)
+		}
+	})
+	return flags, globalFlags
+}
+
+// commandUsage returns the usage line of c, listing its own flags and its
+// arguments
+func commandUsage(appName string, c Command, flags *flag.FlagSet) string {
+	return appName + ` ` + c.Name() + ` ` + FlagString(flags) + ` ` + ArgumentAsString(c.Arguments())
 }
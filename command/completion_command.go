@@ -0,0 +1,201 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/posener/complete"
+	flag "github.com/spf13/pflag"
+)
+
+// completionShells are the shells completion scripts are generated for
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// CompletionCommand prints shell completion scripts for the cli tool, or
+// installs them for the current user with --install. The scripts call back
+// into the cli tool to complete the command line, so completions always
+// reflect the registered commands and their AutocompleteFlags and
+// AutocompleteArgs. The cli tool must be on the PATH for the scripts to
+// find it.
+type CompletionCommand struct {
+	Meta
+
+	install bool
+}
+
+func (c *CompletionCommand) Help() string {
+	return CommandHelp(c)
+}
+
+func (c *CompletionCommand) Arguments() []Argument {
+	args := []Argument{}
+	args = append(args, Argument{
+		Name:        "shell",
+		Description: "the shell to generate completions for, defaulting to the shell in $SHELL",
+		Optional:    true,
+		Type:        ArgumentEnum,
+		Choices:     completionShells,
+	})
+	return args
+}
+
+func (c *CompletionCommand) AutocompleteFlags() complete.Flags {
+	return MergeAutocompleteFlags(
+		c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"--install": complete.PredictNothing,
+		},
+	)
+}
+
+func (c *CompletionCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictSet(completionShells...)
+}
+
+func (c *CompletionCommand) Examples() map[string]string {
+	appName := c.AppName()
+	return map[string]string{
+		"Load completions into the current bash session": fmt.Sprintf("source <(%s %s bash)", appName, c.Name()),
+		"Install completions for the current shell":      fmt.Sprintf("%s %s --install", appName, c.Name()),
+	}
+}
+
+func (c *CompletionCommand) FlagSet() *flag.FlagSet {
+	f := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	f.BoolVar(&c.install, "install", false, "install the completion script for the current user instead of printing it")
+	return f
+}
+
+func (c *CompletionCommand) Name() string {
+	return "completion"
+}
+
+func (c *CompletionCommand) ParsedArguments(args []string) (map[string]Argument, error) {
+	return c.ParseArguments(args, c.Arguments())
+}
+
+func (c *CompletionCommand) Synopsis() string {
+	return "Generate shell completion scripts"
+}
+
+func (c *CompletionCommand) Run(args []string) int {
+	return c.RunCommand(c, args)
+}
+
+func (c *CompletionCommand) Execute(ctx context.Context, arguments map[string]Argument) error {
+	shell := arguments["shell"].EnumValue()
+	if shell == "" {
+		shell = filepath.Base(c.Getenv("SHELL"))
+		if !containsString(completionShells, shell) {
+			return NewUsageError("Unable to detect the shell from $SHELL, specify one of %s", strings.Join(completionShells, ", "))
+		}
+	}
+
+	script := CompletionScript(c.AppName(), shell)
+	if !c.install {
+		c.Ui.Output(script)
+		return nil
+	}
+
+	path, err := c.installScript(shell, script)
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		c.Ui.Info(fmt.Sprintf("Completions for %s are already installed", shell))
+		return nil
+	}
+
+	c.Ui.Info(fmt.Sprintf("Installed completions for %s in %s, restart the shell to load them", shell, path))
+	return nil
+}
+
+// installScript installs script for shell, returning the file it was
+// written to, or an empty string if it was already installed. Fish loads
+// completions from a file per command, while the bash and zsh scripts are
+// appended to the rc file of the shell.
+func (c *CompletionCommand) installScript(shell string, script string) (string, error) {
+	home := c.Getenv("HOME")
+	if home == "" {
+		var err error
+		if home, err = os.UserHomeDir(); err != nil {
+			return "", NewInternalError("Unable to find the home directory: %s", err.Error())
+		}
+	}
+
+	var path string
+	switch shell {
+	case "bash":
+		path = filepath.Join(home, ".bashrc")
+	case "zsh":
+		path = filepath.Join(home, ".zshrc")
+	case "fish":
+		configHome := c.Getenv("XDG_CONFIG_HOME")
+		if configHome == "" {
+			configHome = filepath.Join(home, ".config")
+		}
+		path = filepath.Join(configHome, "fish", "completions", c.AppName()+".fish")
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return "", NewInternalError("Unable to create completions directory: %s", err.Error())
+		}
+		if err := os.WriteFile(path, []byte(script+"\n"), 0o644); err != nil {
+			return "", NewInternalError("Unable to write completion script: %s", err.Error())
+		}
+		return path, nil
+	}
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", NewInternalError("Unable to read %s: %s", path, err.Error())
+	}
+	if strings.Contains(string(existing), script) {
+		return "", nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return "", NewInternalError("Unable to open %s: %s", path, err.Error())
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintf(f, "\n%s\n", script); err != nil {
+		return "", NewInternalError("Unable to write to %s: %s", path, err.Error())
+	}
+	return path, nil
+}
+
+// CompletionScript returns the completion script of the cli tool appName
+// for shell, which is one of bash, zsh or fish. The script runs the cli
+// tool with the command line being completed in the COMP_LINE env var,
+// which App answers with the completions for the registered commands.
+func CompletionScript(appName string, shell string) string {
+	switch shell {
+	case "bash":
+		return fmt.Sprintf("# %[1]s completion for bash\ncomplete -C %[1]s %[1]s", appName)
+	case "zsh":
+		return fmt.Sprintf("# %[1]s completion for zsh\nautoload -U +X bashcompinit && bashcompinit\ncomplete -o nospace -C %[1]s %[1]s", appName)
+	case "fish":
+		return fmt.Sprintf(`# %[1]s completion for fish
+function __complete_%[1]s
+    set -lx COMP_LINE (commandline -cp)
+    test -z (commandline -ct)
+    and set COMP_LINE "$COMP_LINE "
+    %[1]s
+end
+complete -f -c %[1]s -a "(__complete_%[1]s)"`, appName)
+	}
+	return ""
+}
+
+// containsString returns whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
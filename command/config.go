@@ -0,0 +1,543 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	flag "github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// configExtensions are the config file formats that are supported, in the
+// order they are searched for.
+var configExtensions = []string{".toml", ".yaml", ".yml", ".json"}
+
+// Config holds the settings loaded from the config files of a cli tool.
+// Top-level keys apply to every command with a flag of the same name, while
+// keys in a section named after a command only apply to that command:
+//
+//	# ~/.config/hello-world/config.toml
+//	color = "red"
+//
+//	[eat]
+//	count = 2
+//
+// Settings may also be grouped into named profiles under the profiles key,
+// which override other settings while the profile is active. The profile
+// key selects the profile that is active by default:
+//
+//	profile = "staging"
+//
+//	[profiles.staging]
+//	color = "blue"
+//
+//	[profiles.staging.eat]
+//	count = 5
+type Config struct {
+	// Files are the config files that were loaded, from lowest to
+	// highest precedence
+	Files []string
+
+	global   map[string]configValue
+	sections map[string]map[string]configValue
+
+	// Named profiles and the name of the active profile, if any
+	profiles map[string]*Config
+	active   string
+}
+
+// configValue is a config setting along with the file it was loaded from
+// and the precedence of that file
+type configValue struct {
+	value      interface{}
+	file       string
+	precedence int
+}
+
+// ConfigPaths returns the config files that are searched for the cli tool
+// appName, from lowest to highest precedence:
+//
+//   - config.{toml,yaml,yml,json} in the appName directory of each
+//     $XDG_CONFIG_DIRS entry, defaulting to /etc/xdg
+//   - config.{toml,yaml,yml,json} in the appName directory of
+//     $XDG_CONFIG_HOME, defaulting to ~/.config
+//   - .appName.{toml,yaml,yml,json} in the current directory
+//
+// Only the first file found with each name is loaded.
+func ConfigPaths(appName string) []string {
+	return configPaths(appName, ".", os.Getenv)
+}
+
+// configPaths implements ConfigPaths, reading env vars with getenv and
+// treating dir as the current directory
+func configPaths(appName string, dir string, getenv func(string) string) []string {
+	dirs := []string{}
+
+	configDirs := getenv("XDG_CONFIG_DIRS")
+	if configDirs == "" {
+		configDirs = "/etc/xdg"
+	}
+	systemDirs := filepath.SplitList(configDirs)
+	for i := len(systemDirs) - 1; i >= 0; i-- {
+		dirs = append(dirs, filepath.Join(systemDirs[i], appName))
+	}
+
+	if dir := userConfigDir(appName, getenv); dir != "" {
+		dirs = append(dirs, dir)
+	}
+
+	paths := []string{}
+	for _, dir := range dirs {
+		if path := findConfigFile(dir, "config"); path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	if path := findConfigFile(dir, "."+appName); path != "" {
+		paths = append(paths, path)
+	}
+
+	return paths
+}
+
+// UserConfigDir returns the directory holding the config files of the user
+// for the cli tool appName, or an empty string if it cannot be determined.
+func UserConfigDir(appName string) string {
+	return userConfigDir(appName, os.Getenv)
+}
+
+// userConfigDir implements UserConfigDir, reading env vars with getenv
+func userConfigDir(appName string, getenv func(string) string) string {
+	configHome := getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home := getenv("HOME")
+		if home == "" {
+			var err error
+			if home, err = os.UserHomeDir(); err != nil {
+				return ""
+			}
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(configHome, appName)
+}
+
+// findConfigFile returns the first file in dir named name with a supported
+// config file extension, or an empty string if there is none.
+func findConfigFile(dir string, name string) string {
+	for _, ext := range configExtensions {
+		path := filepath.Join(dir, name+ext)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// LoadConfig loads the config files of the cli tool appName found by
+// ConfigPaths. If configFile is not empty, it is loaded last and so takes
+// precedence over the other config files, and must exist.
+func LoadConfig(appName string, configFile string) (*Config, error) {
+	return loadConfig(appName, ".", configFile, os.Getenv)
+}
+
+// loadConfig implements LoadConfig, reading env vars with getenv and
+// treating dir as the current directory
+func loadConfig(appName string, dir string, configFile string, getenv func(string) string) (*Config, error) {
+	c := newConfig()
+
+	paths := configPaths(appName, dir, getenv)
+	if configFile != "" {
+		configFile = resolvePath(dir, configFile)
+		if _, err := os.Stat(configFile); err != nil {
+			return nil, fmt.Errorf("Unable to load config file: %w", err)
+		}
+		paths = append(paths, configFile)
+	}
+
+	for _, path := range paths {
+		values, err := ReadConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+		c.Files = append(c.Files, path)
+		c.merge(path, len(c.Files), values)
+	}
+
+	return c, nil
+}
+
+func newConfig() *Config {
+	return &Config{
+		Files:    []string{},
+		global:   map[string]configValue{},
+		sections: map[string]map[string]configValue{},
+		profiles: map[string]*Config{},
+	}
+}
+
+// ReadConfigFile reads the settings in the config file at path, using the
+// format matching its extension. A missing file has no settings.
+func ReadConfigFile(path string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read config file %s: %w", path, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".json":
+		err = json.Unmarshal(data, &values)
+	default:
+		return nil, fmt.Errorf("Unsupported config file format %s, expected one of %s", path, strings.Join(configExtensions, ", "))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse config file %s: %w", path, err)
+	}
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+
+	return values, nil
+}
+
+// WriteConfigFile writes values to the config file at path, using the
+// format matching its extension. Any missing parent directories are
+// created. Comments and formatting in an existing file are not preserved.
+func WriteConfigFile(path string, values map[string]interface{}) error {
+	var data []byte
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		buf := new(bytes.Buffer)
+		err = toml.NewEncoder(buf).Encode(values)
+		data = buf.Bytes()
+	case ".yaml", ".yml":
+		data, err = yaml.Marshal(values)
+	case ".json":
+		data, err = json.MarshalIndent(values, "", "  ")
+		data = append(data, '\n')
+	default:
+		return fmt.Errorf("Unsupported config file format %s, expected one of %s", path, strings.Join(configExtensions, ", "))
+	}
+
+	if err != nil {
+		return fmt.Errorf("Unable to encode config file %s: %w", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("Unable to create config directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("Unable to write config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// merge adds the settings loaded from file to the config, overriding any
+// existing settings with the same key
+func (c *Config) merge(file string, precedence int, values map[string]interface{}) {
+	for key, value := range values {
+		if profiles, ok := value.(map[string]interface{}); ok && key == "profiles" {
+			for name, profileValues := range profiles {
+				settings, ok := profileValues.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if c.profiles[name] == nil {
+					c.profiles[name] = newConfig()
+				}
+				c.profiles[name].Files = append(c.profiles[name].Files, file)
+				c.profiles[name].merge(file, precedence, settings)
+			}
+			continue
+		}
+
+		section, ok := value.(map[string]interface{})
+		if !ok {
+			c.global[key] = configValue{value: value, file: file, precedence: precedence}
+			continue
+		}
+
+		if c.sections[key] == nil {
+			c.sections[key] = map[string]configValue{}
+		}
+		for sectionKey, sectionValue := range section {
+			c.sections[key][sectionKey] = configValue{value: sectionValue, file: file, precedence: precedence}
+		}
+	}
+}
+
+// Lookup returns the setting key for the command named command, along with
+// the config file it was loaded from. Settings in the active profile take
+// precedence over all other settings. Otherwise settings from config files
+// with a higher precedence win, and within a single config file settings
+// in the section of the command take precedence over top-level settings.
+func (c *Config) Lookup(command string, key string) (interface{}, string, bool) {
+	if c == nil {
+		return nil, "", false
+	}
+
+	if profile := c.profiles[c.active]; profile != nil {
+		if value, file, ok := profile.Lookup(command, key); ok {
+			return value, file, true
+		}
+	}
+
+	section, inSection := c.sections[command][key]
+	global, inGlobal := c.global[key]
+	switch {
+	case inSection && (!inGlobal || section.precedence >= global.precedence):
+		return section.value, section.file, true
+	case inGlobal:
+		return global.value, global.file, true
+	}
+	return nil, "", false
+}
+
+// Get returns the setting key, which is either a top-level key such as
+// "color" or a key in a command section such as "eat.count", along with
+// the config file it was loaded from.
+func (c *Config) Get(key string) (interface{}, string, bool) {
+	if c == nil {
+		return nil, "", false
+	}
+
+	section, name := splitConfigKey(key)
+	v, ok := c.global[name]
+	if section != "" {
+		v, ok = c.sections[section][name]
+	}
+	if !ok {
+		return nil, "", false
+	}
+	return v.value, v.file, true
+}
+
+// Keys returns the keys of every setting in the config, sorted
+// alphabetically. Keys in command sections are prefixed with the name of
+// the command, such as "eat.count".
+func (c *Config) Keys() []string {
+	keys := []string{}
+	if c == nil {
+		return keys
+	}
+
+	for key := range c.global {
+		keys = append(keys, key)
+	}
+	for section, values := range c.sections {
+		for key := range values {
+			keys = append(keys, section+"."+key)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// Profiles returns the names of the profiles defined in the config, sorted
+// alphabetically.
+func (c *Config) Profiles() []string {
+	names := []string{}
+	if c == nil {
+		return names
+	}
+
+	for name := range c.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profile returns the settings of the profile name, or nil if there is no
+// such profile.
+func (c *Config) Profile(name string) *Config {
+	if c == nil {
+		return nil
+	}
+	return c.profiles[name]
+}
+
+// ActiveProfile returns the name of the active profile, or an empty
+// string if no profile is active.
+func (c *Config) ActiveProfile() string {
+	if c == nil {
+		return ""
+	}
+	return c.active
+}
+
+// selectProfile activates the profile specified by the --profile flag in
+// args, the APPNAME_PROFILE env var or the profile key of the config, in
+// that order of precedence. It is an error to select a profile that is not
+// defined with the flag or env var. A profile key naming a profile that is
+// not defined is likely left over from a profile that has been removed,
+// so no profile is activated and a warning is returned instead, leaving
+// the user able to select another profile.
+func (c *Config) selectProfile(appName string, args []string, getenv func(string) string) (string, error) {
+	name := flagValueFromArgs(args, "profile")
+	source := "--profile flag"
+	if name == "" {
+		env := envName(appName, "profile")
+		name = getenv(env)
+		source = "env var " + env
+	}
+
+	fromConfig := false
+	if name == "" {
+		if value, file, ok := c.Get("profile"); ok {
+			name = fmt.Sprint(value)
+			source = "config file " + file
+			fromConfig = true
+		}
+	}
+
+	if name == "" {
+		return "", nil
+	}
+
+	if c.profiles[name] == nil {
+		if fromConfig {
+			return fmt.Sprintf("Profile %s specified by the %s does not exist, no profile is used", name, source), nil
+		}
+		return "", &NotFoundError{Err: fmt.Errorf("Profile %s specified by the %s does not exist", name, source)}
+	}
+
+	c.active = name
+	return "", nil
+}
+
+// splitConfigKey splits key into the command section and the key within
+// that section. Top-level keys have an empty section.
+func splitConfigKey(key string) (string, string) {
+	if idx := strings.LastIndex(key, "."); idx != -1 {
+		return key[:idx], key[idx+1:]
+	}
+	return "", key
+}
+
+// Config returns the settings loaded from config files, or nil if config
+// files are not enabled via App.Config.
+func (m *Meta) Config() *Config {
+	return m.config
+}
+
+// configPath returns the config file that settings are written to by
+// commands such as ConfigCommand and ProfileCommand
+func (m *Meta) configPath() string {
+	if m.configFile != "" {
+		return resolvePath(m.workingDir(), m.configFile)
+	}
+
+	dir := userConfigDir(m.AppName(), m.Getenv)
+	if path := findConfigFile(dir, "config"); path != "" {
+		return path
+	}
+	return filepath.Join(dir, "config.toml")
+}
+
+// workingDir returns the directory that config files are looked up in
+// in place of the current directory
+func (m *Meta) workingDir() string {
+	if m.dir == "" {
+		return "."
+	}
+	return m.dir
+}
+
+// resolvePath returns path resolved against dir if it is relative
+func resolvePath(dir string, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// applyFlagConfig sets every unchanged flag in f to its value in the
+// loaded config files, if any. Config sections are looked up by the name
+// of f, which is the name of the command for flag sets returned by
+// Meta.FlagSet.
+func (m *Meta) applyFlagConfig(f *flag.FlagSet) error {
+	if m.config == nil {
+		return nil
+	}
+
+	var err error
+	f.VisitAll(func(fl *flag.Flag) {
+		if err != nil || fl.Changed || fl.Name == "config" || fl.Name == "profile" {
+			return
+		}
+
+		value, file, ok := m.config.Lookup(f.Name(), fl.Name)
+		if !ok {
+			return
+		}
+
+		values := []interface{}{value}
+		if list, ok := value.([]interface{}); ok {
+			values = list
+		}
+
+		for _, v := range values {
+			s := configScalarString(v)
+			if setErr := f.Set(fl.Name, s); setErr != nil {
+				err = fmt.Errorf("Invalid value %q for %s in config file %s: %s", s, fl.Name, file, setErr.Error())
+				return
+			}
+		}
+		m.flagSources[fl.Name] = ValueSource{Type: SourceConfig, Name: file}
+	})
+	return err
+}
+
+// configScalarString renders a single config value as it would be given on
+// the command line. JSON numbers are decoded as float64, which fmt renders
+// in exponent form for large values, so floats are rendered without one.
+func configScalarString(value interface{}) string {
+	if f, ok := value.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// flagValueFromArgs returns the value of the flag name in args, if any.
+// Arguments after a "--" terminator are ignored.
+func flagValueFromArgs(args []string, name string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+
+		if strings.HasPrefix(arg, "--"+name+"=") {
+			return strings.TrimPrefix(arg, "--"+name+"=")
+		}
+
+		if arg == "--"+name && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+
+	return ""
+}
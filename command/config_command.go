@@ -0,0 +1,434 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/posener/complete"
+	flag "github.com/spf13/pflag"
+)
+
+// ConfigCommand views and edits the settings in the config file of the cli
+// tool. It requires config files to be enabled via App.Config. Settings are
+// written to the file specified by --config or, failing that, the config
+// file in UserConfigDir, which is created as config.toml if missing. When
+// --profile is specified, the settings of that profile are used instead.
+type ConfigCommand struct {
+	Meta
+}
+
+func (c *ConfigCommand) Help() string {
+	return CommandHelp(c)
+}
+
+func (c *ConfigCommand) Arguments() []Argument {
+	args := []Argument{}
+	args = append(args, Argument{
+		Name:        "action",
+		Description: "the action to perform",
+		Optional:    false,
+		Type:        ArgumentEnum,
+		Choices:     []string{"get", "set", "unset", "list", "edit"},
+	})
+	args = append(args, Argument{
+		Name:        "key",
+		Description: "the setting to get, set or unset, such as color or eat.count",
+		Optional:    true,
+		Type:        ArgumentString,
+	})
+	args = append(args, Argument{
+		Name:        "value",
+		Description: "the value to set",
+		Optional:    true,
+		Type:        ArgumentString,
+	})
+	return args
+}
+
+func (c *ConfigCommand) AutocompleteFlags() complete.Flags {
+	return MergeAutocompleteFlags(
+		c.Meta.AutocompleteFlags(FlagSetClient|FlagSetFormat),
+		complete.Flags{},
+	)
+}
+
+func (c *ConfigCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictSet("get", "set", "unset", "list", "edit")
+}
+
+func (c *ConfigCommand) Examples() map[string]string {
+	appName := c.AppName()
+	return map[string]string{
+		"List all settings":                  fmt.Sprintf("%s %s list", appName, c.Name()),
+		"Get a setting":                      fmt.Sprintf("%s %s get color", appName, c.Name()),
+		"Set a setting for every command":    fmt.Sprintf("%s %s set color red", appName, c.Name()),
+		"Set a setting for a single command": fmt.Sprintf("%s %s set eat.count 2", appName, c.Name()),
+		"Remove a setting":                   fmt.Sprintf("%s %s unset color", appName, c.Name()),
+		"Edit the config file":               fmt.Sprintf("%s %s edit", appName, c.Name()),
+	}
+}
+
+func (c *ConfigCommand) FlagSet() *flag.FlagSet {
+	return c.Meta.FlagSet(c.Name(), FlagSetClient|FlagSetFormat)
+}
+
+func (c *ConfigCommand) Name() string {
+	return "config"
+}
+
+func (c *ConfigCommand) ParsedArguments(args []string) (map[string]Argument, error) {
+	return c.ParseArguments(args, c.Arguments())
+}
+
+func (c *ConfigCommand) Synopsis() string {
+	return "View and edit settings"
+}
+
+func (c *ConfigCommand) Run(args []string) int {
+	return c.RunCommand(c, args)
+}
+
+func (c *ConfigCommand) Execute(ctx context.Context, arguments map[string]Argument) error {
+	if c.Config() == nil {
+		return NewInternalError("Config files are not enabled for %s", c.AppName())
+	}
+
+	action := arguments["action"].EnumValue()
+	key := arguments["key"].StringValue()
+	value := arguments["value"].StringValue()
+
+	needsKey := action == "get" || action == "set" || action == "unset"
+	if needsKey && key == "" {
+		return NewUsageError("The %s action requires a key", action)
+	}
+	if !needsKey && key != "" {
+		return NewUsageError("The %s action does not accept a key", action)
+	}
+	if action == "set" && !arguments["value"].HasValue {
+		return NewUsageError("The set action requires a value")
+	}
+	if action != "set" && arguments["value"].HasValue {
+		return NewUsageError("The %s action does not accept a value", action)
+	}
+
+	switch action {
+	case "get":
+		return c.get(key)
+	case "set":
+		return c.set(key, value)
+	case "unset":
+		return c.unset(key)
+	case "list":
+		return c.list()
+	case "edit":
+		return c.edit(ctx)
+	}
+
+	return nil
+}
+
+func (c *ConfigCommand) get(key string) error {
+	if _, err := c.lookupFlag(key); err != nil {
+		return err
+	}
+
+	value, _, ok := c.settings().Get(key)
+	if !ok {
+		return NewNotFoundError("%s is not set", key)
+	}
+
+	return c.output(value, formatConfigValue(value))
+}
+
+func (c *ConfigCommand) set(key string, value string) error {
+	fl, err := c.lookupFlag(key)
+	if err != nil {
+		return err
+	}
+
+	typed, err := typedConfigValue(fl, value)
+	if err != nil {
+		return NewUsageError("Invalid value %q for %s: %s", value, key, err.Error())
+	}
+
+	path := c.configPath()
+	values, err := ReadConfigFile(path)
+	if err != nil {
+		return err
+	}
+
+	target := values
+	if profile := c.profileFlag(); profile != "" {
+		if target, err = nestedConfigMap(values, path, "profiles", profile); err != nil {
+			return err
+		}
+	}
+
+	section, name := splitConfigKey(key)
+	if section != "" {
+		if target, err = nestedConfigMap(target, path, section); err != nil {
+			return err
+		}
+	}
+	target[name] = typed
+
+	return WriteConfigFile(path, values)
+}
+
+func (c *ConfigCommand) unset(key string) error {
+	if _, err := c.lookupFlag(key); err != nil {
+		return err
+	}
+
+	path := c.configPath()
+	values, err := ReadConfigFile(path)
+	if err != nil {
+		return err
+	}
+
+	target := values
+	if profile := c.profileFlag(); profile != "" {
+		profiles, _ := values["profiles"].(map[string]interface{})
+		target, _ = profiles[profile].(map[string]interface{})
+	}
+
+	section, name := splitConfigKey(key)
+	if section == "" {
+		if _, ok := target[name]; !ok {
+			return NewNotFoundError("%s is not set in %s", key, path)
+		}
+		delete(target, name)
+	} else {
+		sectionValues, ok := target[section].(map[string]interface{})
+		if _, exists := sectionValues[name]; !ok || !exists {
+			return NewNotFoundError("%s is not set in %s", key, path)
+		}
+		delete(sectionValues, name)
+		if len(sectionValues) == 0 {
+			delete(target, section)
+		}
+	}
+
+	return WriteConfigFile(path, values)
+}
+
+func (c *ConfigCommand) list() error {
+	settings := c.listedSettings()
+	lines := make([]string, 0, len(settings))
+	for _, setting := range settings {
+		lines = append(lines, fmt.Sprintf("%s = %s  # %s", setting.Key, formatConfigValue(setting.Value), setting.Source))
+	}
+
+	return c.output(settings, strings.Join(lines, "\n"))
+}
+
+// configSetting is a setting listed by the list action, along with where
+// its value came from
+type configSetting struct {
+	Key    string      `json:"key" yaml:"key"`
+	Value  interface{} `json:"value" yaml:"value"`
+	Source string      `json:"source" yaml:"source"`
+}
+
+// listedSettings returns the settings listed by the list action, sorted by
+// key. Unless --profile is specified, the settings of the active profile
+// replace the top-level settings with the same key.
+func (c *ConfigCommand) listedSettings() []configSetting {
+	settings := map[string]configSetting{}
+	add := func(config *Config, profile string) {
+		for _, key := range config.Keys() {
+			value, file, _ := config.Get(key)
+			source := ValueSource{Type: SourceConfig, Name: file}.String()
+			if profile != "" {
+				source += " (profile " + profile + ")"
+			}
+			settings[key] = configSetting{Key: key, Value: value, Source: source}
+		}
+	}
+
+	if profile := c.profileFlag(); profile != "" {
+		add(c.Config().Profile(profile), profile)
+	} else {
+		add(c.Config(), "")
+		if profile := c.Config().ActiveProfile(); profile != "" {
+			add(c.Config().Profile(profile), profile)
+		}
+	}
+
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	listed := make([]configSetting, 0, len(keys))
+	for _, key := range keys {
+		listed = append(listed, settings[key])
+	}
+	return listed
+}
+
+func (c *ConfigCommand) edit(ctx context.Context) error {
+	path := c.configPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("Unable to create config directory: %w", err)
+	}
+
+	editor := c.Getenv("VISUAL")
+	if editor == "" {
+		editor = c.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	parts := strings.Fields(editor)
+	cmd := exec.CommandContext(ctx, parts[0], append(parts[1:], path)...)
+	cmd.Stdin = c.Stdin()
+	cmd.Stdout = c.Stdout()
+	cmd.Stderr = c.Stderr()
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("Unable to edit config file %s: %w", path, err)
+	}
+
+	// Catch syntax errors now rather than on the next run
+	_, err := ReadConfigFile(path)
+	return err
+}
+
+// output renders value in the selected output format, or writes text for
+// the human format
+func (c *ConfigCommand) output(value interface{}, text string) error {
+	if c.Format() != FormatHuman {
+		return c.Render(value)
+	}
+
+	if text != "" {
+		c.Ui.Output(text)
+	}
+	return nil
+}
+
+// profileFlag returns the profile specified by the --profile flag, whose
+// settings are used instead of the top-level settings
+func (c *ConfigCommand) profileFlag() string {
+	if c.FlagSource("profile").Type == SourceFlag {
+		return c.Profile()
+	}
+	return ""
+}
+
+// settings returns the settings that are read by get and list
+func (c *ConfigCommand) settings() *Config {
+	if profile := c.profileFlag(); profile != "" {
+		return c.Config().Profile(profile)
+	}
+	return c.Config()
+}
+
+// nestedConfigMap returns the map at keys within values, creating any
+// maps that are missing
+func nestedConfigMap(values map[string]interface{}, path string, keys ...string) (map[string]interface{}, error) {
+	for _, key := range keys {
+		nested, ok := values[key].(map[string]interface{})
+		if !ok {
+			if _, exists := values[key]; exists {
+				return nil, fmt.Errorf("Unable to update %s: %s is not a section", path, key)
+			}
+			nested = map[string]interface{}{}
+			values[key] = nested
+		}
+		values = nested
+	}
+	return values, nil
+}
+
+// lookupFlag returns the flag that the config key is applied to. Top-level
+// keys may be applied to the flag of any command, while keys in a command
+// section must match a flag of that command.
+func (c *ConfigCommand) lookupFlag(key string) (*flag.Flag, error) {
+	section, name := splitConfigKey(key)
+	if name == "" || name == "config" || name == "profile" {
+		return nil, NewUsageError("Unknown config key %s", key)
+	}
+
+	if c.commands == nil {
+		return nil, NewInternalError("Unable to validate config key %s: no commands registered", key)
+	}
+
+	if section != "" {
+		if _, ok := c.commands.factories[section]; !ok {
+			return nil, NewUsageError("Unknown command %s in config key %s", section, key)
+		}
+	}
+
+	names := make([]string, 0, len(c.commands.factories))
+	for commandName := range c.commands.factories {
+		names = append(names, commandName)
+	}
+	sort.Strings(names)
+
+	for _, commandName := range names {
+		if section != "" && commandName != section {
+			continue
+		}
+
+		cmd, err := c.commands.factories[commandName]()
+		if err != nil {
+			continue
+		}
+
+		command, ok := cmd.(Command)
+		if !ok {
+			continue
+		}
+
+		if fl := flagSetWithGlobals(command).Lookup(name); fl != nil {
+			return fl, nil
+		}
+	}
+
+	return nil, NewUsageError("Unknown config key %s", key)
+}
+
+// typedConfigValue validates value against fl and converts it to the type
+// of fl, so that it is written to the config file with the right type
+func typedConfigValue(fl *flag.Flag, value string) (interface{}, error) {
+	if err := fl.Value.Set(value); err != nil {
+		return nil, err
+	}
+
+	switch fl.Value.Type() {
+	case "bool":
+		return strconv.ParseBool(value)
+	case "int", "int8", "int16", "int32", "int64":
+		return strconv.ParseInt(value, 10, 64)
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		return strconv.ParseUint(value, 10, 64)
+	case "float32", "float64":
+		return strconv.ParseFloat(value, 64)
+	case "stringSlice", "stringArray":
+		return strings.Split(value, ","), nil
+	}
+
+	return value, nil
+}
+
+// formatConfigValue renders a config value for human readable output
+func formatConfigValue(value interface{}) string {
+	if list, ok := value.([]interface{}); ok {
+		values := make([]string, 0, len(list))
+		for _, v := range list {
+			values = append(values, configScalarString(v))
+		}
+		return strings.Join(values, ",")
+	}
+
+	return configScalarString(value)
+}
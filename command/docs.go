@@ -0,0 +1,405 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	flag "github.com/spf13/pflag"
+)
+
+// Formats of the reference documentation written by WriteDocs
+const (
+	DocsMan      = "man"
+	DocsMarkdown = "markdown"
+)
+
+// commandDoc is the documentation of a command, as rendered into a man
+// page or Markdown page
+type commandDoc struct {
+	name        string
+	synopsis    string
+	description string
+	usage       string
+
+	// help is the help text of commands that do not implement Command,
+	// which is documented as is
+	help string
+
+	flags       []flagDoc
+	globalFlags []flagDoc
+	arguments   []Argument
+	examples    []Example
+}
+
+// flagDoc is the documentation of a flag
+type flagDoc struct {
+	name      string
+	shorthand string
+	typeName  string
+	usage     string
+	defValue  string
+}
+
+// WriteDocs writes reference documentation for every command of the cli
+// tool that is not hidden to dir, without running the cli tool. For
+// DocsMan, a man page is written for each command, named after the cli
+// tool and the command (hello-world-eat.1), along with a man page for the
+// cli tool itself listing its commands (hello-world.1). For DocsMarkdown, a
+// Markdown page is written for each command (eat.md), along with an
+// index.md listing the commands.
+//
+// Config files are not loaded, so the documentation does not depend on
+// the settings of the user generating it.
+func (a *App) WriteDocs(dir string, format string) error {
+	factories := Commands(context.Background(), a.staticMeta(), a.Commands)
+	return writeDocs(dir, format, a.Name, a.Version, factories, a.HiddenCommands)
+}
+
+// WriteDocs writes reference documentation for every command of the cli
+// tool that is not hidden to dir, as described by App.WriteDocs. When m
+// was set up by App.Run, the commands are built as by App.WriteDocs, so
+// that the loaded config files and active profile are not documented.
+func (m *Meta) WriteDocs(dir string, format string) error {
+	if m.app != nil {
+		return m.app.WriteDocs(dir, format)
+	}
+
+	if m.commands == nil {
+		return NewInternalError("Unable to generate docs: no commands registered")
+	}
+	return writeDocs(dir, format, m.appName, m.version, m.commands.factories, m.hiddenCommands)
+}
+
+// ManPage returns the man page of the command c of the cli tool appName, in
+// roff format.
+func ManPage(appName string, version string, c Command) string {
+	return manPage(appName, version, newCommandDoc(appName, c.Name(), c))
+}
+
+// MarkdownPage returns the reference page of the command c of the cli tool
+// appName, in Markdown format.
+func MarkdownPage(appName string, c Command) string {
+	return markdownPage(appName, newCommandDoc(appName, c.Name(), c))
+}
+
+// writeDocs implements WriteDocs for the given commands
+func writeDocs(dir string, format string, appName string, version string, factories map[string]cli.CommandFactory, hidden []string) error {
+	if format != DocsMan && format != DocsMarkdown {
+		return NewUsageError("Invalid docs format %s, must be one of %s or %s", format, DocsMan, DocsMarkdown)
+	}
+
+	names := []string{}
+	for name := range factories {
+		if !containsString(hidden, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return NewInternalError("Unable to create docs directory: %s", err.Error())
+	}
+
+	docs := []commandDoc{}
+	for _, name := range names {
+		c, err := factories[name]()
+		if err != nil {
+			return NewInternalError("Unable to load command %s: %s", name, err.Error())
+		}
+
+		doc := cliCommandDoc(appName, name, c)
+		docs = append(docs, doc)
+
+		filename, content := name+".md", markdownPage(appName, doc)
+		if format == DocsMan {
+			filename, content = manPageName(appName, name)+".1", manPage(appName, version, doc)
+		}
+		if err := writeDocsFile(dir, strings.ReplaceAll(filename, " ", "-"), content); err != nil {
+			return err
+		}
+	}
+
+	if format == DocsMan {
+		return writeDocsFile(dir, appName+".1", manIndex(appName, version, docs))
+	}
+	return writeDocsFile(dir, "index.md", markdownIndex(appName, docs))
+}
+
+// writeDocsFile writes content to the file name in dir
+func writeDocsFile(dir string, name string, content string) error {
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		return NewInternalError("Unable to write docs: %s", err.Error())
+	}
+	return nil
+}
+
+// cliCommandDoc returns the documentation of the command c registered as
+// name, which is documented by its help text if it does not implement
+// Command
+func cliCommandDoc(appName string, name string, c cli.Command) commandDoc {
+	if command, ok := c.(Command); ok {
+		return newCommandDoc(appName, name, command)
+	}
+
+	return commandDoc{
+		name:     name,
+		synopsis: c.Synopsis(),
+		help:     strings.TrimSpace(c.Help()),
+	}
+}
+
+// newCommandDoc returns the documentation of the command c registered as
+// name
+func newCommandDoc(appName string, name string, command Command) commandDoc {
+	doc := commandDoc{
+		name:     name,
+		synopsis: command.Synopsis(),
+	}
+
+	if d, ok := command.(DescribedCommand); ok {
+		doc.description = strings.TrimSpace(d.Description())
+	}
+
+	flags, globalFlags := commandFlagSets(command)
+	doc.usage = strings.TrimSpace(commandUsage(appName, command, flags))
+	doc.flags = flagDocs(flags)
+	if globalFlags != nil {
+		doc.globalFlags = flagDocs(globalFlags)
+	}
+	doc.arguments = command.Arguments()
+
+	doc.examples = CommandExamples(command)
+	return doc
+}
+
+// flagDocs returns the documentation of the flags in f that are not hidden
+func flagDocs(f *flag.FlagSet) []flagDoc {
+	docs := []flagDoc{}
+	f.VisitAll(func(fl *flag.Flag) {
+		if fl.Hidden {
+			return
+		}
+
+		typeName, usage := flag.UnquoteUsage(fl)
+		doc := flagDoc{
+			name:      fl.Name,
+			shorthand: fl.Shorthand,
+			typeName:  typeName,
+			usage:     usage,
+		}
+		switch fl.DefValue {
+		case "", "false", "0", "[]", "0s":
+		default:
+			doc.defValue = fl.DefValue
+		}
+		docs = append(docs, doc)
+	})
+	return docs
+}
+
+// manPageName returns the name of the man page of the command name
+func manPageName(appName string, name string) string {
+	return appName + "-" + strings.ReplaceAll(name, " ", "-")
+}
+
+// manHeader returns the title line of a man page
+func manHeader(appName string, version string, title string) string {
+	source := appName
+	if version != "" {
+		source += " " + version
+	}
+	return fmt.Sprintf(".TH \"%s\" \"1\" \"\" \"%s\" \"%s\"\n", roffEscape(strings.ToUpper(title)), roffEscape(source), roffEscape(appName+" Manual"))
+}
+
+// manPage renders doc as a man page
+func manPage(appName string, version string, doc commandDoc) string {
+	b := new(strings.Builder)
+	b.WriteString(manHeader(appName, version, manPageName(appName, doc.name)))
+	fmt.Fprintf(b, ".SH NAME\n%s \\- %s\n", roffEscape(manPageName(appName, doc.name)), roffEscape(doc.synopsis))
+
+	if doc.help != "" {
+		fmt.Fprintf(b, ".SH DESCRIPTION\n.nf\n%s\n.fi\n", roffEscape(doc.help))
+	} else {
+		fmt.Fprintf(b, ".SH SYNOPSIS\n\\fB%s\\fR\n", roffEscape(doc.usage))
+		description := doc.synopsis
+		if doc.description != "" {
+			description = doc.description
+		}
+		fmt.Fprintf(b, ".SH DESCRIPTION\n%s\n", strings.ReplaceAll(roffEscape(description), "\n\n", "\n.PP\n"))
+	}
+
+	manFlags(b, "OPTIONS", doc.flags)
+	manFlags(b, "GLOBAL OPTIONS", doc.globalFlags)
+
+	if len(doc.arguments) > 0 {
+		b.WriteString(".SH ARGUMENTS\n")
+		for _, argument := range doc.arguments {
+			b.WriteString(".TP\n\\fB" + roffEscape(argumentPlaceholder(argument)) + "\\fR")
+			if typeName := argumentTypeName(argument); typeName != "" {
+				b.WriteString(" \\fI" + roffEscape(typeName) + "\\fR")
+			}
+			b.WriteString("\n" + roffEscape(argumentUsage(argument)) + "\n")
+		}
+	}
+
+	if len(doc.examples) > 0 {
+		b.WriteString(".SH EXAMPLES\n")
+		for _, example := range doc.examples {
+			fmt.Fprintf(b, ".PP\n%s\n.PP\n.RS\n.nf\n$ %s\n", roffEscape(example.Description), roffEscape(example.Command))
+			if output := strings.TrimRight(example.Output, "\n"); output != "" {
+				b.WriteString(roffEscape(output) + "\n")
+			}
+			b.WriteString(".fi\n.RE\n")
+		}
+	}
+
+	fmt.Fprintf(b, ".SH SEE ALSO\n.BR %s (1)\n", roffEscape(appName))
+	return b.String()
+}
+
+// manFlags renders flags as a section of a man page
+func manFlags(b *strings.Builder, section string, flags []flagDoc) {
+	if len(flags) == 0 {
+		return
+	}
+
+	b.WriteString(".SH " + section + "\n")
+	for _, f := range flags {
+		b.WriteString(".TP\n")
+		if f.shorthand != "" {
+			b.WriteString("\\fB" + roffEscape("-"+f.shorthand) + "\\fR, ")
+		}
+		b.WriteString("\\fB" + roffEscape("--"+f.name) + "\\fR")
+		if f.typeName != "" {
+			b.WriteString(" \\fI" + roffEscape(f.typeName) + "\\fR")
+		}
+
+		usage := f.usage
+		if f.defValue != "" {
+			usage += fmt.Sprintf(" (default %s)", f.defValue)
+		}
+		b.WriteString("\n" + roffEscape(usage) + "\n")
+	}
+}
+
+// manIndex renders the man page of the cli tool, listing its commands
+func manIndex(appName string, version string, docs []commandDoc) string {
+	b := new(strings.Builder)
+	b.WriteString(manHeader(appName, version, appName))
+	fmt.Fprintf(b, ".SH NAME\n%s \\- the %s cli tool\n", roffEscape(appName), roffEscape(appName))
+	fmt.Fprintf(b, ".SH SYNOPSIS\n\\fB%s\\fR [\\-\\-version] [\\-\\-help] \\fIcommand\\fR [\\fIargs\\fR]\n", roffEscape(appName))
+
+	if len(docs) > 0 {
+		b.WriteString(".SH COMMANDS\n")
+		for _, doc := range docs {
+			fmt.Fprintf(b, ".TP\n\\fB%s\\fR\n%s\n", roffEscape(doc.name), roffEscape(doc.synopsis))
+		}
+
+		pages := make([]string, len(docs))
+		for i, doc := range docs {
+			pages[i] = ".BR " + roffEscape(manPageName(appName, doc.name)) + " (1)"
+		}
+		b.WriteString(".SH SEE ALSO\n" + strings.Join(pages, ",\n") + "\n")
+	}
+	return b.String()
+}
+
+// roffEscape escapes s for use in a man page
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// markdownPage renders doc as a Markdown page
+func markdownPage(appName string, doc commandDoc) string {
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "# %s %s\n\n%s\n", appName, doc.name, doc.synopsis)
+	if doc.description != "" {
+		fmt.Fprintf(b, "\n%s\n", doc.description)
+	}
+
+	if doc.help != "" {
+		fmt.Fprintf(b, "\n```\n%s\n```\n", doc.help)
+	} else {
+		fmt.Fprintf(b, "\n## Usage\n\n```\n%s\n```\n", doc.usage)
+	}
+
+	markdownFlags(b, "Options", doc.flags)
+	markdownFlags(b, "Global Options", doc.globalFlags)
+
+	if len(doc.arguments) > 0 {
+		b.WriteString("\n## Arguments\n\n| Argument | Type | Description |\n| --- | --- | --- |\n")
+		for _, argument := range doc.arguments {
+			fmt.Fprintf(b, "| `%s` | %s | %s |\n", argumentPlaceholder(argument), markdownCell(argumentTypeName(argument)), markdownCell(argumentUsage(argument)))
+		}
+	}
+
+	if len(doc.examples) > 0 {
+		b.WriteString("\n## Examples\n")
+		for _, example := range doc.examples {
+			fmt.Fprintf(b, "\n%s\n\n```shell\n$ %s\n", example.Description, example.Command)
+			if output := strings.TrimRight(example.Output, "\n"); output != "" {
+				b.WriteString(output + "\n")
+			}
+			b.WriteString("```\n")
+		}
+	}
+
+	fmt.Fprintf(b, "\n## See also\n\n- [%s](index.md)\n", appName)
+	return b.String()
+}
+
+// markdownFlags renders flags as a section of a Markdown page
+func markdownFlags(b *strings.Builder, section string, flags []flagDoc) {
+	if len(flags) == 0 {
+		return
+	}
+
+	fmt.Fprintf(b, "\n## %s\n\n| Flag | Type | Default | Description |\n| --- | --- | --- | --- |\n", section)
+	for _, f := range flags {
+		name := "`--" + f.name + "`"
+		if f.shorthand != "" {
+			name = "`-" + f.shorthand + "`, " + name
+		}
+
+		defValue := ""
+		if f.defValue != "" {
+			defValue = "`" + f.defValue + "`"
+		}
+		fmt.Fprintf(b, "| %s | %s | %s | %s |\n", name, markdownCell(f.typeName), markdownCell(defValue), markdownCell(f.usage))
+	}
+}
+
+// markdownIndex renders the index page of the cli tool, listing its
+// commands
+func markdownIndex(appName string, docs []commandDoc) string {
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "# %s\n\n```\n%s [--version] [--help] <command> [<args>]\n```\n", appName, appName)
+
+	if len(docs) > 0 {
+		b.WriteString("\n## Commands\n\n| Command | Description |\n| --- | --- |\n")
+		for _, doc := range docs {
+			fmt.Fprintf(b, "| [%s](%s.md) | %s |\n", doc.name, strings.ReplaceAll(doc.name, " ", "-"), markdownCell(doc.synopsis))
+		}
+	}
+	return b.String()
+}
+
+// markdownCell escapes s for use in a Markdown table cell
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
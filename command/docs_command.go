@@ -0,0 +1,88 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/posener/complete"
+	flag "github.com/spf13/pflag"
+)
+
+// DocsCommand writes man pages or Markdown reference documentation for the
+// commands of the cli tool, as described by App.WriteDocs. It is intended
+// for packaging and publishing the cli tool rather than for its users, so
+// it should be listed in App.HiddenCommands.
+type DocsCommand struct {
+	Meta
+
+	output string
+}
+
+func (c *DocsCommand) Help() string {
+	return CommandHelp(c)
+}
+
+func (c *DocsCommand) Arguments() []Argument {
+	args := []Argument{}
+	args = append(args, Argument{
+		Name:        "format",
+		Description: "the format of the documentation",
+		Optional:    false,
+		Type:        ArgumentEnum,
+		Choices:     []string{DocsMan, DocsMarkdown},
+	})
+	return args
+}
+
+func (c *DocsCommand) AutocompleteFlags() complete.Flags {
+	return MergeAutocompleteFlags(
+		c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{
+			"--output": complete.PredictDirs("*"),
+		},
+	)
+}
+
+func (c *DocsCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictSet(DocsMan, DocsMarkdown)
+}
+
+func (c *DocsCommand) Examples() map[string]string {
+	appName := c.AppName()
+	return map[string]string{
+		"Write man pages to the man directory":           fmt.Sprintf("%s %s %s --output man", appName, c.Name(), DocsMan),
+		"Write Markdown reference to the docs directory": fmt.Sprintf("%s %s %s", appName, c.Name(), DocsMarkdown),
+	}
+}
+
+func (c *DocsCommand) FlagSet() *flag.FlagSet {
+	f := c.Meta.FlagSet(c.Name(), FlagSetClient)
+	f.StringVarP(&c.output, "output", "o", "docs", "the directory to write the documentation to")
+	return f
+}
+
+func (c *DocsCommand) Name() string {
+	return "docs"
+}
+
+func (c *DocsCommand) ParsedArguments(args []string) (map[string]Argument, error) {
+	return c.ParseArguments(args, c.Arguments())
+}
+
+func (c *DocsCommand) Synopsis() string {
+	return "Generate reference documentation"
+}
+
+func (c *DocsCommand) Run(args []string) int {
+	return c.RunCommand(c, args)
+}
+
+func (c *DocsCommand) Execute(ctx context.Context, arguments map[string]Argument) error {
+	format := arguments["format"].EnumValue()
+	if err := c.WriteDocs(c.output, format); err != nil {
+		return err
+	}
+
+	c.Ui.Info(fmt.Sprintf("Wrote %s documentation to %s", format, c.output))
+	return nil
+}
@@ -0,0 +1,175 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	flag "github.com/spf13/pflag"
+)
+
+// flagAnnotationEnv is the flag annotation holding the name of the env var
+// a flag falls back to when it is not specified on the command line.
+const flagAnnotationEnv = "cli-skeleton-env"
+
+// SetFlagEnv binds the flag name in f to the env var env, which is used as
+// the value of the flag when it is not specified on the command line. The
+// env var is shown in the usage of the flag. Flags are resolved in the
+// order flag > env > default by Meta.ParseFlags.
+func SetFlagEnv(f *flag.FlagSet, name string, env string) {
+	fl := f.Lookup(name)
+	if fl == nil {
+		panic(fmt.Errorf("Cannot bind env var %s to undefined flag %s", env, name))
+	}
+
+	if existing := FlagEnv(fl); existing != "" {
+		if existing == env {
+			return
+		}
+		fl.Usage = strings.TrimSuffix(fl.Usage, envUsage(existing))
+	}
+
+	if err := f.SetAnnotation(name, flagAnnotationEnv, []string{env}); err != nil {
+		panic(err)
+	}
+	fl.Usage += envUsage(env)
+}
+
+// FlagEnv returns the name of the env var bound to fl, or an empty string
+// if there is none.
+func FlagEnv(fl *flag.Flag) string {
+	if env, ok := fl.Annotations[flagAnnotationEnv]; ok && len(env) > 0 {
+		return env[0]
+	}
+	return ""
+}
+
+// BindFlagEnv binds every flag in f without an env var to one derived from
+// the name of the cli tool and the flag - for example --dry-run of the
+// hello-world cli tool is bound to HELLO_WORLD_DRY_RUN - if automatic env
+// vars are enabled via App.AutoEnv. The --no-color, --config and --profile
+// flags are left as is, as they are read before the command is run.
+func (m *Meta) BindFlagEnv(f *flag.FlagSet) {
+	if !m.autoEnv || m.appName == "" {
+		return
+	}
+
+	f.VisitAll(func(fl *flag.Flag) {
+		if fl.Name == "no-color" || fl.Name == "config" || fl.Name == "profile" || FlagEnv(fl) != "" {
+			return
+		}
+		SetFlagEnv(f, fl.Name, envName(m.appName, fl.Name))
+	})
+}
+
+// ParseFlags parses args into f, then sets every flag that was not
+// specified on the command line to the value of its env var or, failing
+// that, the value from the loaded config files. The source of the value
+// of each flag is available from FlagSource once parsed.
+func (m *Meta) ParseFlags(f *flag.FlagSet, args []string) error {
+	m.BindFlagEnv(f)
+	if err := f.Parse(args); err != nil {
+		return err
+	}
+
+	m.flagSources = map[string]ValueSource{}
+	f.Visit(func(fl *flag.Flag) {
+		m.flagSources[fl.Name] = ValueSource{Type: SourceFlag}
+	})
+
+	if err := m.applyFlagEnv(f); err != nil {
+		return err
+	}
+
+	return m.applyFlagConfig(f)
+}
+
+// FlagSource returns where the value of the flag name came from when the
+// flags of the command were last parsed by ParseFlags.
+func (m *Meta) FlagSource(name string) ValueSource {
+	if source, ok := m.flagSources[name]; ok {
+		return source
+	}
+	return ValueSource{Type: SourceDefault}
+}
+
+// applyFlagEnv sets every unchanged flag in f bound to a non-empty env var
+// to the value of the env var.
+func (m *Meta) applyFlagEnv(f *flag.FlagSet) error {
+	var err error
+	f.VisitAll(func(fl *flag.Flag) {
+		env := FlagEnv(fl)
+		if err != nil || fl.Changed || env == "" {
+			return
+		}
+
+		value := m.Getenv(env)
+		if value == "" {
+			return
+		}
+
+		if setErr := f.Set(fl.Name, value); setErr != nil {
+			err = fmt.Errorf("Invalid value %q for env var %s: %s", value, env, setErr.Error())
+			return
+		}
+		m.flagSources[fl.Name] = ValueSource{Type: SourceEnv, Name: env}
+	})
+	return err
+}
+
+// SourceType is an enum to define where the value of a flag came from.
+type SourceType uint
+
+const (
+	SourceDefault SourceType = iota
+	SourceFlag
+	SourceEnv
+	SourceConfig
+)
+
+func (t SourceType) String() string {
+	switch t {
+	case SourceFlag:
+		return "flag"
+	case SourceEnv:
+		return "env"
+	case SourceConfig:
+		return "config"
+	}
+	return "default"
+}
+
+// ValueSource describes where the value of a flag came from. Name is the
+// env var or config file the value was read from, if any.
+type ValueSource struct {
+	Type SourceType
+	Name string
+}
+
+func (s ValueSource) String() string {
+	switch s.Type {
+	case SourceEnv:
+		return "env $" + s.Name
+	case SourceConfig:
+		return "config " + s.Name
+	}
+	return s.Type.String()
+}
+
+// envName returns the env var name for the flag or argument name of the
+// cli tool appName, with any characters that are not letters or digits
+// replaced by an underscore.
+func envName(appName string, name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, appName+"_"+name)
+}
+
+// envUsage returns the suffix added to the usage of flags and arguments
+// bound to env
+func envUsage(env string) string {
+	return fmt.Sprintf(" (env $%s)", env)
+}
@@ -0,0 +1,139 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// Exit codes returned for errors, following the conventions of sysexits.h
+// where applicable.
+const (
+	// ExitCodeOK is returned when a command succeeds.
+	ExitCodeOK = 0
+
+	// ExitCodeError is returned for errors without a more specific type.
+	ExitCodeError = 1
+
+	// ExitCodeUsage is returned when a command is invoked incorrectly
+	// (EX_USAGE).
+	ExitCodeUsage = 64
+
+	// ExitCodeNotFound is returned when an input does not exist
+	// (EX_NOINPUT).
+	ExitCodeNotFound = 66
+
+	// ExitCodeInternal is returned for internal software errors
+	// (EX_SOFTWARE).
+	ExitCodeInternal = 70
+
+	// ExitCodePermission is returned when the user has insufficient
+	// permission to perform an operation (EX_NOPERM).
+	ExitCodePermission = 77
+)
+
+// ExitCoder is implemented by errors that carry the exit code the cli
+// tool should exit with.
+type ExitCoder interface {
+	error
+	ExitCode() int
+}
+
+// UsageError is returned when a command is invoked incorrectly, such as
+// with an unknown flag or the wrong number of arguments.
+type UsageError struct {
+	Err error
+}
+
+// NewUsageError returns a UsageError formatted as per fmt.Errorf.
+func NewUsageError(format string, a ...interface{}) error {
+	return &UsageError{Err: fmt.Errorf(format, a...)}
+}
+
+func (e *UsageError) Error() string { return e.Err.Error() }
+func (e *UsageError) Unwrap() error { return e.Err }
+func (e *UsageError) ExitCode() int { return ExitCodeUsage }
+
+// NotFoundError is returned when a requested resource does not exist.
+type NotFoundError struct {
+	Err error
+}
+
+// NewNotFoundError returns a NotFoundError formatted as per fmt.Errorf.
+func NewNotFoundError(format string, a ...interface{}) error {
+	return &NotFoundError{Err: fmt.Errorf(format, a...)}
+}
+
+func (e *NotFoundError) Error() string { return e.Err.Error() }
+func (e *NotFoundError) Unwrap() error { return e.Err }
+func (e *NotFoundError) ExitCode() int { return ExitCodeNotFound }
+
+// PermissionError is returned when the user is not permitted to perform
+// the requested operation.
+type PermissionError struct {
+	Err error
+}
+
+// NewPermissionError returns a PermissionError formatted as per fmt.Errorf.
+func NewPermissionError(format string, a ...interface{}) error {
+	return &PermissionError{Err: fmt.Errorf(format, a...)}
+}
+
+func (e *PermissionError) Error() string { return e.Err.Error() }
+func (e *PermissionError) Unwrap() error { return e.Err }
+func (e *PermissionError) ExitCode() int { return ExitCodePermission }
+
+// CancelledError is returned when a command stops early because its
+// context was cancelled, usually due to a shutdown signal.
+type CancelledError struct {
+	Err error
+}
+
+// NewCancelledError returns a CancelledError formatted as per fmt.Errorf.
+func NewCancelledError(format string, a ...interface{}) error {
+	return &CancelledError{Err: fmt.Errorf(format, a...)}
+}
+
+func (e *CancelledError) Error() string { return e.Err.Error() }
+func (e *CancelledError) Unwrap() error { return e.Err }
+func (e *CancelledError) ExitCode() int { return ExitCodeInterrupt }
+
+// InternalError is returned when a command fails due to a bug or an
+// unexpected condition rather than anything the user did.
+type InternalError struct {
+	Err error
+}
+
+// NewInternalError returns an InternalError formatted as per fmt.Errorf.
+func NewInternalError(format string, a ...interface{}) error {
+	return &InternalError{Err: fmt.Errorf(format, a...)}
+}
+
+func (e *InternalError) Error() string { return e.Err.Error() }
+func (e *InternalError) Unwrap() error { return e.Err }
+func (e *InternalError) ExitCode() int { return ExitCodeInternal }
+
+// ExitCodeForError returns the exit code a cli tool should exit with
+// when a command returns err.
+func ExitCodeForError(err error) int {
+	if err == nil {
+		return ExitCodeOK
+	}
+
+	var exitCoder ExitCoder
+	if errors.As(err, &exitCoder) {
+		return exitCoder.ExitCode()
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ExitCodeInterrupt
+	case errors.Is(err, fs.ErrNotExist):
+		return ExitCodeNotFound
+	case errors.Is(err, fs.ErrPermission):
+		return ExitCodePermission
+	}
+
+	return ExitCodeError
+}
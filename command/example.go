@@ -0,0 +1,108 @@
+package command
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// Example is an example invocation of a command, as rendered in its help
+// and reference documentation.
+type Example struct {
+	// Description describes what the example does
+	Description string
+
+	// Command is the command line of the example, starting with the name
+	// of the cli tool
+	Command string
+
+	// Output is the expected stdout of the example, if any. It is shown
+	// below the command line in the help, and checked by
+	// clitest.Harness.RunExamples.
+	Output string
+}
+
+// OrderedExamplesCommand is implemented by commands that list their
+// examples in a given order, optionally with their expected output. The
+// Examples method required by Command is ignored for such commands, and
+// may return nil.
+type OrderedExamplesCommand interface {
+	OrderedExamples() []Example
+}
+
+// CommandExamples returns the examples of c, which are its OrderedExamples
+// if implemented, and otherwise its Examples sorted by description.
+func CommandExamples(c Command) []Example {
+	if o, ok := c.(OrderedExamplesCommand); ok {
+		return o.OrderedExamples()
+	}
+
+	examples := c.Examples()
+	descriptions := make([]string, 0, len(examples))
+	for description := range examples {
+		descriptions = append(descriptions, description)
+	}
+	sort.Strings(descriptions)
+
+	ordered := make([]Example, 0, len(examples))
+	for _, description := range descriptions {
+		ordered = append(ordered, Example{Description: description, Command: examples[description]})
+	}
+	return ordered
+}
+
+// Examples returns the examples of every command of the cli tool that
+// implements Command, keyed by the name the command is registered as, in
+// the order described by CommandExamples. Config files are not loaded, as
+// with WriteDocs.
+func (a *App) Examples() map[string][]Example {
+	examples := map[string][]Example{}
+	for name, factory := range Commands(context.Background(), a.staticMeta(), a.Commands) {
+		c, err := factory()
+		if err != nil {
+			continue
+		}
+		if command, ok := c.(Command); ok {
+			examples[name] = CommandExamples(command)
+		}
+	}
+	return examples
+}
+
+// ExampleString renders examples as shown in the help of a command, sorted
+// by description.
+func ExampleString(examples map[string]string) string {
+	ordered := []Example{}
+	for description, command := range examples {
+		ordered = append(ordered, Example{Description: description, Command: command})
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		return ordered[i].Description < ordered[j].Description
+	})
+	return FormatExamples(ordered)
+}
+
+// FormatExamples renders examples as shown in the help of a command, in
+// the given order, with the expected output of each example below its
+// command line.
+func FormatExamples(examples []Example) string {
+	return FormatExamplesWrapped(examples, 0)
+}
+
+// FormatExamplesWrapped renders examples as FormatExamples does, wrapping
+// their descriptions to cols columns. Command lines and output are not
+// wrapped, so that they can be copied as is. Pass cols == 0 to do no
+// wrapping.
+func FormatExamplesWrapped(examples []Example, cols int) string {
+	exampleString := []string{}
+
+	for _, example := range examples {
+		s := "  " + wrap(2, cols, example.Description) + "\n    $ " + example.Command
+		if output := strings.TrimRight(example.Output, "\n"); output != "" {
+			s += "\n    " + strings.ReplaceAll(output, "\n", "\n    ")
+		}
+		exampleString = append(exampleString, s)
+	}
+
+	return strings.Join(exampleString, "\n\n")
+}
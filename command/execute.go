@@ -0,0 +1,94 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	flag "github.com/spf13/pflag"
+)
+
+// ExecuteCommand is a Command that reports failure by returning an error
+// rather than printing it and returning an exit code. Its Run function
+// should delegate to Meta.RunCommand, which handles flag and argument
+// parsing, error output and exit codes:
+//
+//	func (c *EatCommand) Run(args []string) int {
+//		return c.RunCommand(c, args)
+//	}
+type ExecuteCommand interface {
+	Command
+	Help() string
+	Execute(ctx context.Context, arguments map[string]Argument) error
+}
+
+// argumentParser is implemented by commands that override argument parsing.
+type argumentParser interface {
+	ParsedArguments(args []string) (map[string]Argument, error)
+}
+
+// RunCommand parses args into the flags and arguments of c - falling back
+// to env vars for flags that are not specified and populating the Options
+// of an OptionsCommand - executes it and returns the exit code for the
+// error it returned, if any. Errors are written to the Ui, followed by a
+// pointer to the command help for usage errors. A warning is written
+// first if c is a DeprecatedCommand.
+func (m *Meta) RunCommand(c ExecuteCommand, args []string) int {
+	if message := deprecationMessage(c); message != "" {
+		m.Ui.Warn(fmt.Sprintf("Warning: the %s command is deprecated: %s", c.Name(), message))
+	}
+
+	err := m.execute(c, args)
+	if err == nil {
+		return ExitCodeOK
+	}
+
+	m.Ui.Error(err.Error())
+
+	var usageErr *UsageError
+	if errors.As(err, &usageErr) {
+		m.Ui.Error(CommandErrorText(c))
+	}
+
+	return ExitCodeForError(err)
+}
+
+func (m *Meta) execute(c ExecuteCommand, args []string) error {
+	flags := flagSetWithGlobals(c)
+	flags.Usage = func() { m.Ui.Output(c.Help()) }
+	if err := m.ParseFlags(flags, args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return &UsageError{Err: err}
+	}
+
+	var arguments map[string]Argument
+	var err error
+	if p, ok := c.(argumentParser); ok {
+		arguments, err = p.ParsedArguments(flags.Args())
+	} else {
+		arguments, err = m.ParseArguments(flags.Args(), c.Arguments())
+	}
+	if err != nil {
+		// Invalid argument definitions are not the fault of the user
+		var internalErr *InternalError
+		if errors.As(err, &internalErr) {
+			return err
+		}
+		return &UsageError{Err: err}
+	}
+
+	if o, ok := c.(OptionsCommand); ok {
+		if err := PopulateOptions(o.Options(), arguments); err != nil {
+			return &InternalError{Err: err}
+		}
+	}
+
+	ctx := m.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return c.Execute(ctx, arguments)
+}
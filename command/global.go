@@ -0,0 +1,157 @@
+package command
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+	flag "github.com/spf13/pflag"
+)
+
+// AutocompleteGlobalFlagCommand is implemented by GlobalFlagCommand
+// implementations that provide custom completions for their global
+// flags. Global flags are otherwise completed without predicting values.
+type AutocompleteGlobalFlagCommand interface {
+	AutocompleteGlobalFlags() complete.Flags
+}
+
+// GlobalFlagSet returns the global flags of cmd, or nil if cmd does not
+// implement GlobalFlagCommand.
+func GlobalFlagSet(cmd interface{}) *flag.FlagSet {
+	g, ok := cmd.(GlobalFlagCommand)
+	if !ok {
+		return nil
+	}
+
+	f := flag.NewFlagSet("global", flag.ContinueOnError)
+	g.GlobalFlags(f)
+	return f
+}
+
+// flagSetWithGlobals returns the FlagSet of c with the global flags of c
+// merged in. Global flags the command already defines itself are left as is.
+func flagSetWithGlobals(c Command) *flag.FlagSet {
+	f := c.FlagSet()
+	if gf := GlobalFlagSet(c); gf != nil {
+		f.AddFlagSet(gf)
+	}
+	return f
+}
+
+// globalFlagCommand returns the first command in commands implementing
+// GlobalFlagCommand, or nil if there is none.
+func globalFlagCommand(commands map[string]cli.CommandFactory) cli.Command {
+	keys := make([]string, 0, len(commands))
+	for key := range commands {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		cmd, err := commands[key]()
+		if err != nil {
+			continue
+		}
+
+		if _, ok := cmd.(GlobalFlagCommand); ok {
+			return cmd
+		}
+	}
+
+	return nil
+}
+
+// AutocompleteGlobalFlagsFor returns the flag completions for the global
+// flags of cmd.
+func AutocompleteGlobalFlagsFor(cmd interface{}) complete.Flags {
+	gf := GlobalFlagSet(cmd)
+	if gf == nil {
+		return nil
+	}
+
+	flags := complete.Flags{}
+	gf.VisitAll(func(fl *flag.Flag) {
+		if fl.NoOptDefVal != "" {
+			flags["--"+fl.Name] = complete.PredictNothing
+		} else {
+			flags["--"+fl.Name] = complete.PredictAnything
+		}
+	})
+
+	if a, ok := cmd.(AutocompleteGlobalFlagCommand); ok {
+		flags = MergeAutocompleteFlags(flags, a.AutocompleteGlobalFlags())
+	}
+
+	return flags
+}
+
+// reorderGlobalFlags moves global flags specified before the subcommand
+// name to after it, as mitchellh/cli rejects unknown flags that precede
+// the subcommand. Flags that are not global are left in place, and args
+// are returned as is if there is no subcommand.
+func reorderGlobalFlags(args []string, globals *flag.FlagSet) []string {
+	if globals == nil {
+		return args
+	}
+
+	leading := []string{}
+	moved := []string{}
+	subcommand := -1
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+
+		if arg == "" || arg[0] != '-' || arg == "-" {
+			subcommand = i
+			break
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		hasValue := false
+		if idx := strings.Index(name, "="); idx != -1 {
+			name = name[:idx]
+			hasValue = true
+		}
+
+		var fl *flag.Flag
+		if strings.HasPrefix(arg, "--") {
+			fl = globals.Lookup(name)
+		} else if len(name) == 1 {
+			fl = globals.ShorthandLookup(name)
+		}
+
+		if fl == nil {
+			leading = append(leading, arg)
+			continue
+		}
+
+		moved = append(moved, arg)
+		if !hasValue && fl.NoOptDefVal == "" && i+1 < len(args) {
+			i++
+			moved = append(moved, args[i])
+		}
+	}
+
+	if subcommand == -1 || len(moved) == 0 {
+		return args
+	}
+
+	rest := args[subcommand:]
+	terminator := len(rest)
+	for i, arg := range rest {
+		if arg == "--" {
+			terminator = i
+			break
+		}
+	}
+
+	reordered := make([]string, 0, len(args))
+	reordered = append(reordered, leading...)
+	reordered = append(reordered, rest[:terminator]...)
+	reordered = append(reordered, moved...)
+	reordered = append(reordered, rest[terminator:]...)
+	return reordered
+}
@@ -0,0 +1,40 @@
+package command_test
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/josegonzalez/cli-skeleton/command"
+	"github.com/josegonzalez/cli-skeleton/command/clitest"
+)
+
+// update is the -update flag of golden tests, which must not conflict with
+// clitest
+var update = flag.Bool("update", false, "rewrite golden files with the actual output")
+
+func TestArgumentsString(t *testing.T) {
+	t.Parallel()
+
+	arguments := []command.Argument{
+		{Name: "name", Description: "the name of the lollipop", Type: command.ArgumentString},
+		{Name: "count", Description: "the number of lollipops", Type: command.ArgumentInt, Optional: true, Default: 1},
+		{Name: "speed", Description: "how quickly to eat the lollipops", Type: command.ArgumentEnum, Choices: []string{"quickly", "slowly"}, Optional: true, Default: "slowly"},
+		{Name: "tags", Description: "tags to add to the lollipops, which are shown when listing them", Type: command.ArgumentList, Optional: true, Default: []string{"sweet", "sour"}},
+	}
+
+	tests := []struct {
+		name string
+		cols int
+	}{
+		{name: "unwrapped", cols: 0},
+		{name: "wrapped", cols: 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			clitest.AssertGolden(t, t.Name(), command.ArgumentsStringWrapped(arguments, tt.cols))
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package command
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	flag "github.com/spf13/pflag"
+)
+
+// DefaultHelpTemplate is the text/template used by CommandHelp to render
+// the help of a command, with HelpData as its data and the sprig template
+// functions available. Sections are separated by blank lines, so a custom
+// template can add sections by appending to it:
+//
+//	command.DefaultHelpTemplate + `
+//
+//	Environment:
+//
+//	  HELLO_WORLD_COUNT   the default number of lollipops to eat`
+const DefaultHelpTemplate = `Usage: {{ .Usage }}
+
+  {{ .Synopsis }}
+{{- with .Description }}
+
+{{ indent 2 . }}
+{{- end }}
+{{- with .Options }}
+
+Options:
+
+{{ . }}
+{{- end }}
+{{- with .GlobalOptions }}
+
+Global Options:
+
+{{ . }}
+{{- end }}
+{{- with .Arguments }}
+
+Arguments:
+
+{{ . }}
+{{- end }}
+{{- with .Examples }}
+
+Examples:
+
+{{ . }}
+{{- end }}`
+
+// DescribedCommand is implemented by commands with a longer description
+// than their Synopsis, which may span multiple paragraphs. The description
+// is rendered below the synopsis in the help of the command.
+type DescribedCommand interface {
+	Description() string
+}
+
+// HelpData is the data rendered by the help template of a command. The
+// Options, GlobalOptions, Arguments and Examples sections are rendered as
+// they appear in the default help, without trailing newlines, and are
+// empty if the command has none. The Description is wrapped to fit the
+// Width once indented by two columns, as in the default help.
+type HelpData struct {
+	AppName     string
+	Name        string
+	Usage       string
+	Synopsis    string
+	Description string
+
+	Options       string
+	GlobalOptions string
+	Arguments     string
+	Examples      string
+
+	// Width is the width in columns the sections are wrapped to, as
+	// returned by Meta.HelpWidth
+	Width int
+
+	// Command is the command the help is rendered for, and Flags and
+	// GlobalFlags its own flags and global flags, for templates that
+	// render the sections differently
+	Command     Command
+	Flags       *flag.FlagSet
+	GlobalFlags *flag.FlagSet
+}
+
+// CommandHelpTemplate returns the template used by CommandHelp to render
+// the help of commands, which is App.HelpTemplate if set and
+// DefaultHelpTemplate otherwise. Commands may define their own
+// CommandHelpTemplate method to restyle only their own help. Note that
+// mitchellh/cli renders the result of a HelpTemplate method as a template
+// of its own, so that name must not be used.
+func (m *Meta) CommandHelpTemplate() string {
+	if m.helpTemplate == "" {
+		return DefaultHelpTemplate
+	}
+	return m.helpTemplate
+}
+
+// newHelpData returns the data rendered by the help template of c
+func newHelpData(c Command) HelpData {
+	appName := appNameFor(c)
+	width := helpWidthFor(c)
+	flags, globalFlags := commandFlagSets(c)
+
+	data := HelpData{
+		AppName:   appName,
+		Name:      c.Name(),
+		Usage:     commandUsage(appName, c, flags),
+		Synopsis:  c.Synopsis(),
+		Options:   strings.TrimRight(flags.FlagUsagesWrapped(width), "\n"),
+		Arguments: strings.TrimRight(ArgumentsStringWrapped(c.Arguments(), width), "\n"),
+		Examples:  FormatExamplesWrapped(CommandExamples(c), width),
+		Width:     width,
+		Command:   c,
+		Flags:     flags,
+	}
+
+	if d, ok := c.(DescribedCommand); ok {
+		// The default template indents the description by two columns
+		data.Description = wrap(0, width-2, reflow(strings.TrimSpace(d.Description())))
+	}
+
+	if globalFlags != nil {
+		data.GlobalOptions = strings.TrimRight(globalFlags.FlagUsagesWrapped(width), "\n")
+		data.GlobalFlags = globalFlags
+	}
+	return data
+}
+
+// reflow joins the lines of every paragraph of s, so that they can be
+// wrapped to another width than they were written for. Indented lines and
+// list items starting with "- " or "* " are kept on lines of their own.
+func reflow(s string) string {
+	lines := strings.Split(s, "\n")
+	buf := new(bytes.Buffer)
+	for i, line := range lines {
+		if i > 0 {
+			if keepsLine(lines[i-1]) || keepsLine(line) {
+				buf.WriteString("\n")
+			} else {
+				buf.WriteString(" ")
+			}
+		}
+		buf.WriteString(line)
+	}
+	return buf.String()
+}
+
+// keepsLine returns whether line is kept on a line of its own by reflow
+func keepsLine(line string) bool {
+	if strings.TrimSpace(line) == "" {
+		return true
+	}
+	return strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") ||
+		strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ")
+}
+
+// renderHelp renders data with the help template text, removing trailing
+// whitespace from every line
+func renderHelp(text string, data HelpData) (string, error) {
+	tmpl, err := template.New("help").Funcs(sprig.TxtFuncMap()).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := tmpl.Execute(buf, data); err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
@@ -24,10 +24,21 @@ func MergeAutocompleteFlags(flags ...complete.Flags) complete.Flags {
 // CommandErrorText is used to easily render the same messaging across commads
 // when an error is printed.
 func CommandErrorText(cmd NamedCommand) string {
-	appName := os.Getenv("CLI_APP_NAME")
+	appName := appNameFor(cmd)
 	return fmt.Sprintf("For additional help try '%s %s --help'", appName, cmd.Name())
 }
 
+// appNameFor returns the name of the cli tool a command belongs to. Commands
+// embedding Meta carry the name of the cli tool; the CLI_APP_NAME env var
+// is only consulted as a fallback for commands that do not.
+func appNameFor(cmd interface{}) string {
+	if c, ok := cmd.(interface{ AppName() string }); ok && c.AppName() != "" {
+		return c.AppName()
+	}
+
+	return os.Getenv(EnvCLIAppName)
+}
+
 // uiErrorWriter is a io.Writer that wraps underlying ui.ErrorWriter().
 // ui.ErrorWriter expects full lines as inputs and it emits its own line breaks.
 //
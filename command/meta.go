@@ -3,6 +3,7 @@ package command
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -10,7 +11,6 @@ import (
 	"github.com/mitchellh/colorstring"
 	"github.com/posener/complete"
 	flag "github.com/spf13/pflag"
-	"golang.org/x/crypto/ssh/terminal"
 )
 
 const (
@@ -24,9 +24,18 @@ const (
 type FlagSetFlags uint
 
 const (
-	FlagSetNone    FlagSetFlags = 0
-	FlagSetClient  FlagSetFlags = 1 << iota
-	FlagSetDefault              = FlagSetClient
+	FlagSetNone   FlagSetFlags = 0
+	FlagSetClient FlagSetFlags = 1 << iota
+
+	// FlagSetFormat adds a --format flag selecting the output format
+	// used by Meta.Render, and a --no-headers flag for table output
+	FlagSetFormat
+
+	// FlagSetPrompt adds a --yes flag that answers prompts with their
+	// defaults, confirming any yes or no questions
+	FlagSetPrompt
+
+	FlagSetDefault = FlagSetClient
 )
 
 // Meta contains the meta-options and functionality that nearly
@@ -36,8 +45,56 @@ type Meta struct {
 
 	Context context.Context
 
+	// The standard streams, environment and terminal of the cli tool,
+	// defaulting to those of the process when nil
+	stdin    io.Reader
+	stdout   io.Writer
+	stderr   io.Writer
+	getenv   func(string) string
+	terminal *Terminal
+
+	// The name and version of the cli tool
+	appName string
+	version string
+
 	// Whether to not-colorize output
 	noColor bool
+
+	// The output format used by Render and whether to omit table headers
+	format    string
+	noHeaders bool
+
+	// The template used to render the help of commands
+	helpTemplate string
+
+	// Whether to answer prompts with their defaults
+	assumeYes bool
+
+	// Whether flags are bound to APPNAME_FLAG_NAME env vars by default
+	autoEnv bool
+
+	// Settings loaded from config files, if enabled
+	config     *Config
+	configFile string
+	profile    string
+
+	// The directory config files are looked up in, in place of the
+	// current directory when not empty
+	dir string
+
+	// The App running the cli tool, if it is run by App.Run
+	app *App
+
+	// Where the value of each flag came from, populated by ParseFlags
+	flagSources map[string]ValueSource
+
+	// The commands of the cli tool, populated by Commands, and the names
+	// of those that are hidden
+	commands       *commandRegistry
+	hiddenCommands []string
+
+	// Handles shutdown signals for Context
+	signals *SignalHandler
 }
 
 // FlagSet returns a FlagSet with the common flags that every
@@ -51,6 +108,22 @@ func (m *Meta) FlagSet(n string, fs FlagSetFlags) *flag.FlagSet {
 	// client connectivity options.
 	if fs&FlagSetClient != 0 {
 		f.BoolVar(&m.noColor, "no-color", false, "disables colored command output. Alternatively, NO_COLOR may be set.")
+
+		// The config file is loaded before the command is run, so the
+		// flag only needs to be accepted here
+		if m.config != nil {
+			f.StringVar(&m.configFile, "config", m.configFile, "path to a config file to load settings from")
+			f.StringVar(&m.profile, "profile", m.profile, fmt.Sprintf("the config profile to use. Alternatively, %s may be set.", envName(m.appName, "profile")))
+		}
+	}
+
+	if fs&FlagSetFormat != 0 {
+		f.Var(&formatValue{value: &m.format}, "format", fmt.Sprintf("the output format, one of %s, %s, %s, %s or a Go template", FormatHuman, FormatJSON, FormatYAML, FormatTable))
+		f.BoolVar(&m.noHeaders, "no-headers", false, "omit the headers of table output")
+	}
+
+	if fs&FlagSetPrompt != 0 {
+		f.BoolVarP(&m.assumeYes, "yes", "y", false, "answer yes to confirmation prompts and use the defaults of other prompts")
 	}
 
 	f.SetOutput(&uiErrorWriter{ui: m.Ui})
@@ -60,23 +133,77 @@ func (m *Meta) FlagSet(n string, fs FlagSetFlags) *flag.FlagSet {
 
 // AutocompleteFlags returns a set of flag completions for the given flag set.
 func (m *Meta) AutocompleteFlags(fs FlagSetFlags) complete.Flags {
-	if fs&FlagSetClient == 0 {
+	if fs&(FlagSetClient|FlagSetFormat|FlagSetPrompt) == 0 {
 		return nil
 	}
 
-	return complete.Flags{
-		"-no-color": complete.PredictNothing,
+	flags := complete.Flags{}
+	if fs&FlagSetClient != 0 {
+		flags["-no-color"] = complete.PredictNothing
+		if m.config != nil {
+			flags["--config"] = complete.PredictFiles("*")
+			flags["--profile"] = complete.PredictSet(m.config.Profiles()...)
+		}
+	}
+
+	if fs&FlagSetFormat != 0 {
+		flags["--format"] = complete.PredictSet(FormatHuman, FormatJSON, FormatYAML, FormatTable)
+		flags["--no-headers"] = complete.PredictNothing
+	}
+
+	if fs&FlagSetPrompt != 0 {
+		flags["--yes"] = complete.PredictNothing
 	}
+	return flags
 }
 
+// AppName returns the name of the cli tool.
+func (m *Meta) AppName() string {
+	return m.appName
+}
+
+// AppVersion returns the version of the cli tool.
+func (m *Meta) AppVersion() string {
+	return m.version
+}
+
+// Profile returns the name of the active config profile, or an empty
+// string if no profile is active.
+func (m *Meta) Profile() string {
+	return m.profile
+}
+
+// SignalHandler returns the handler cancelling Context on SIGINT and
+// SIGTERM, or nil if signals are not being handled.
+func (m *Meta) SignalHandler() *SignalHandler {
+	return m.signals
+}
+
+// ExportLegacyEnv sets the CLI_APP_NAME and CLI_VERSION env vars for
+// commands that read the name and version of the cli tool from the
+// environment instead of using AppName and AppVersion. Note that these
+// env vars are inherited by any child processes.
+func (m *Meta) ExportLegacyEnv() {
+	os.Setenv(EnvCLIAppName, m.appName)
+	os.Setenv(EnvCLIVersion, m.version)
+}
+
+// Colorize returns a Colorize for rendering colorstring markup. Colors are
+// disabled by --no-color, the NO_COLOR env var, or when stdout is not a
+// terminal.
 func (m *Meta) Colorize() *colorstring.Colorize {
 	return &colorstring.Colorize{
 		Colors:  colorstring.DefaultColors,
-		Disable: m.noColor || !terminal.IsTerminal(int(os.Stdout.Fd())),
+		Disable: m.noColor || m.Getenv(EnvCLINoColor) != "" || !m.isTerminal(),
 		Reset:   true,
 	}
 }
 
+// GlobalFlagCommand is implemented by commands that accept flags shared
+// across every command of the cli tool. Global flags are merged into the
+// command's FlagSet by RunCommand, may be specified before or after the
+// subcommand name when using App, and are rendered in a separate
+// "Global Options" section of the command help.
 type GlobalFlagCommand interface {
 	GlobalFlags(*flag.FlagSet)
 }
@@ -89,16 +216,6 @@ func (f funcVar) Set(s string) error { return f(s) }
 func (f funcVar) String() string     { return "" }
 func (f funcVar) IsBoolFlag() bool   { return false }
 
-func ExampleString(examples map[string]string) string {
-	exampleString := []string{}
-
-	for name, example := range examples {
-		exampleString = append(exampleString, "  "+name+"\n    $ "+example)
-	}
-
-	return strings.Join(exampleString, "\n\n")
-}
-
 func FlagString(flags *flag.FlagSet) string {
 	flagString := []string{}
 
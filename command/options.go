@@ -0,0 +1,408 @@
+package command
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/posener/complete"
+	flag "github.com/spf13/pflag"
+)
+
+// OptionsCommand is implemented by commands that declare their flags and
+// arguments as fields of a struct using struct tags:
+//
+//	type EatOptions struct {
+//		Count int    `flag:"count" short:"c" default:"1" help:"number of lollipops to eat"`
+//		Color string `flag:"color" default:"normal" choices:"red,green,blue" help:"the color of the lollipops"`
+//		Speed string `arg:"speed,optional" default:"normally" help:"how quickly to eat the lollipop"`
+//	}
+//
+// The following tags are supported:
+//
+//   - flag: the name of the flag the field is bound to
+//   - short: the one letter shorthand of the flag
+//   - arg: the name of the argument the field is bound to, optionally
+//     followed by "optional", "path" or "file" modifiers
+//   - default: the default value of the flag or argument
+//   - help: the usage of the flag or description of the argument
+//   - env: the env var used when the flag or argument is not specified
+//   - choices: a comma-separated list of the values the flag or argument
+//     accepts
+//
+// Fields may be of type string, int, bool, float64, time.Duration or
+// []string. Options returns a pointer to the struct, which RunCommand
+// populates with the parsed arguments before calling Execute. Flags are
+// bound to the struct fields directly by OptionsFlagSet. Commands may
+// embed OptionsMeta to derive the rest of their methods from the struct.
+type OptionsCommand interface {
+	Options() interface{}
+}
+
+// optionField is a struct field bound to a flag or argument
+type optionField struct {
+	name     string
+	short    string
+	help     string
+	defValue string
+	env      string
+	choices  []string
+	optional bool
+	modifier string
+	isFlag   bool
+	value    reflect.Value
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// optionFields returns the flag and argument fields declared on opts,
+// which must be a pointer to a struct.
+func optionFields(opts interface{}) []optionField {
+	v := reflect.ValueOf(opts)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		panic(fmt.Errorf("Options must be a non-nil pointer to a struct, got %T", opts))
+	}
+
+	v = v.Elem()
+	fields := []optionField{}
+	for i := 0; i < v.NumField(); i++ {
+		structField := v.Type().Field(i)
+		field := optionField{
+			short:    structField.Tag.Get("short"),
+			help:     structField.Tag.Get("help"),
+			defValue: structField.Tag.Get("default"),
+			env:      structField.Tag.Get("env"),
+			value:    v.Field(i),
+		}
+
+		if choices := structField.Tag.Get("choices"); choices != "" {
+			field.choices = strings.Split(choices, ",")
+		}
+
+		if name, ok := structField.Tag.Lookup("flag"); ok {
+			field.name = name
+			field.isFlag = true
+		} else if arg, ok := structField.Tag.Lookup("arg"); ok {
+			parts := strings.Split(arg, ",")
+			field.name = parts[0]
+			for _, modifier := range parts[1:] {
+				switch modifier {
+				case "optional":
+					field.optional = true
+				case "path", "file":
+					field.modifier = modifier
+				default:
+					panic(fmt.Errorf("Unknown arg modifier %q on field %s", modifier, structField.Name))
+				}
+			}
+		} else {
+			continue
+		}
+
+		if !structField.IsExported() {
+			panic(fmt.Errorf("Field %s must be exported to be used as a flag or argument", structField.Name))
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+// parseOptionDefault converts the default tag of field to the field type
+func parseOptionDefault(field optionField) (interface{}, error) {
+	s := field.defValue
+	switch field.value.Type() {
+	case durationType:
+		if s == "" {
+			return time.Duration(0), nil
+		}
+		return time.ParseDuration(s)
+	}
+
+	switch field.value.Kind() {
+	case reflect.String:
+		return s, nil
+	case reflect.Int:
+		if s == "" {
+			return 0, nil
+		}
+		return strconv.Atoi(s)
+	case reflect.Bool:
+		if s == "" {
+			return false, nil
+		}
+		return strconv.ParseBool(s)
+	case reflect.Float64:
+		if s == "" {
+			return float64(0), nil
+		}
+		return strconv.ParseFloat(s, 64)
+	case reflect.Slice:
+		if field.value.Type().Elem().Kind() == reflect.String {
+			if s == "" {
+				return []string{}, nil
+			}
+			return strings.Split(s, ","), nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported type %s", field.value.Type())
+}
+
+// OptionsFlagSet returns the FlagSet returned by FlagSet, with a flag
+// added for every field of opts with a flag tag.
+func (m *Meta) OptionsFlagSet(n string, fs FlagSetFlags, opts interface{}) *flag.FlagSet {
+	f := m.FlagSet(n, fs)
+	for _, field := range optionFields(opts) {
+		if !field.isFlag {
+			continue
+		}
+
+		defValue, err := parseOptionDefault(field)
+		if err != nil {
+			panic(fmt.Errorf("Invalid default for flag %s: %s", field.name, err.Error()))
+		}
+
+		ptr := field.value.Addr().Interface()
+		switch p := ptr.(type) {
+		case *string:
+			if len(field.choices) > 0 {
+				*p = defValue.(string)
+				f.VarP(&choiceValue{value: p, choices: field.choices}, field.name, field.short, field.help)
+			} else {
+				f.StringVarP(p, field.name, field.short, defValue.(string), field.help)
+			}
+		case *int:
+			f.IntVarP(p, field.name, field.short, defValue.(int), field.help)
+		case *bool:
+			f.BoolVarP(p, field.name, field.short, defValue.(bool), field.help)
+		case *float64:
+			f.Float64VarP(p, field.name, field.short, defValue.(float64), field.help)
+		case *time.Duration:
+			f.DurationVarP(p, field.name, field.short, defValue.(time.Duration), field.help)
+		case *[]string:
+			f.StringSliceVarP(p, field.name, field.short, defValue.([]string), field.help)
+		default:
+			panic(fmt.Errorf("Unsupported type %s for flag %s", field.value.Type(), field.name))
+		}
+
+		if field.env != "" {
+			SetFlagEnv(f, field.name, field.env)
+		}
+	}
+
+	return f
+}
+
+// OptionsArguments returns the argument definitions for every field of
+// opts with an arg tag, in field order.
+func OptionsArguments(opts interface{}) []Argument {
+	args := []Argument{}
+	for _, field := range optionFields(opts) {
+		if field.isFlag {
+			continue
+		}
+
+		argument := Argument{
+			Name:        field.name,
+			Description: field.help,
+			Optional:    field.optional,
+			Env:         field.env,
+		}
+
+		switch field.value.Interface().(type) {
+		case string:
+			argument.Type = ArgumentString
+			if len(field.choices) > 0 {
+				argument.Type = ArgumentEnum
+				argument.Choices = field.choices
+			} else if field.modifier == "path" {
+				argument.Type = ArgumentPath
+			} else if field.modifier == "file" {
+				argument.Type = ArgumentFile
+			}
+		case int:
+			argument.Type = ArgumentInt
+		case bool:
+			argument.Type = ArgumentBool
+		case float64:
+			argument.Type = ArgumentFloat
+		case time.Duration:
+			argument.Type = ArgumentDuration
+		case []string:
+			argument.Type = ArgumentList
+			if len(field.choices) > 0 {
+				argument.Validate = ValidateChoices(field.choices...)
+			}
+		default:
+			panic(fmt.Errorf("Unsupported type %s for argument %s", field.value.Type(), field.name))
+		}
+
+		if field.defValue != "" {
+			defValue, err := parseOptionDefault(field)
+			if err != nil {
+				panic(fmt.Errorf("Invalid default for argument %s: %s", field.name, err.Error()))
+			}
+			argument.Default = defValue
+		}
+
+		args = append(args, argument)
+	}
+
+	return args
+}
+
+// AutocompleteOptionsFlags returns flag completions for every field of
+// opts with a flag tag. Flags with choices predict those choices.
+func AutocompleteOptionsFlags(opts interface{}) complete.Flags {
+	flags := complete.Flags{}
+	for _, field := range optionFields(opts) {
+		if !field.isFlag {
+			continue
+		}
+
+		var predictor complete.Predictor = complete.PredictAnything
+		if len(field.choices) > 0 {
+			predictor = complete.PredictSet(field.choices...)
+		} else if field.value.Kind() == reflect.Bool {
+			predictor = complete.PredictNothing
+		}
+
+		flags["--"+field.name] = predictor
+		if field.short != "" {
+			flags["-"+field.short] = predictor
+		}
+	}
+
+	return flags
+}
+
+// AutocompleteOptionsArgs returns argument completions for the arguments
+// declared on opts, predicting the choices of enum arguments and files
+// for path and file arguments.
+func AutocompleteOptionsArgs(opts interface{}) complete.Predictor {
+	predictors := []complete.Predictor{}
+	for _, argument := range OptionsArguments(opts) {
+		switch argument.Type {
+		case ArgumentEnum:
+			predictors = append(predictors, complete.PredictSet(argument.Choices...))
+		case ArgumentPath, ArgumentFile:
+			predictors = append(predictors, complete.PredictFiles("*"))
+		}
+	}
+
+	if len(predictors) == 0 {
+		return complete.PredictNothing
+	}
+	return complete.PredictOr(predictors...)
+}
+
+// OptionsMeta is embedded by commands in place of Meta to derive their
+// flags, arguments and completions from the options struct T, which is
+// declared as described by OptionsCommand:
+//
+//	type EatCommand struct {
+//		command.OptionsMeta[EatOptions]
+//	}
+//
+//	func NewEatCommand(meta command.Meta) *EatCommand {
+//		return &EatCommand{command.NewOptionsMeta[EatOptions](meta, "eat", command.FlagSetClient)}
+//	}
+//
+// It implements the Name, Options, FlagSet, Arguments, AutocompleteFlags
+// and AutocompleteArgs methods of the command, leaving Synopsis, Help,
+// Examples, Run and Execute to be implemented. The parsed values are
+// available in Opts once Execute is called by RunCommand.
+type OptionsMeta[T any] struct {
+	Meta
+
+	// Opts holds the values of the flags and arguments of the command
+	Opts T
+
+	name  string
+	flags FlagSetFlags
+}
+
+// NewOptionsMeta returns an OptionsMeta for the command name, with the
+// common flags specified by fs, as passed to Meta.FlagSet.
+func NewOptionsMeta[T any](meta Meta, name string, fs FlagSetFlags) OptionsMeta[T] {
+	return OptionsMeta[T]{Meta: meta, name: name, flags: fs}
+}
+
+func (m *OptionsMeta[T]) Name() string {
+	return m.name
+}
+
+func (m *OptionsMeta[T]) Options() interface{} {
+	return &m.Opts
+}
+
+func (m *OptionsMeta[T]) Arguments() []Argument {
+	return OptionsArguments(m.Options())
+}
+
+func (m *OptionsMeta[T]) AutocompleteArgs() complete.Predictor {
+	return AutocompleteOptionsArgs(m.Options())
+}
+
+func (m *OptionsMeta[T]) FlagSet() *flag.FlagSet {
+	return m.Meta.OptionsFlagSet(m.name, m.flags, m.Options())
+}
+
+func (m *OptionsMeta[T]) AutocompleteFlags() complete.Flags {
+	return MergeAutocompleteFlags(
+		m.Meta.AutocompleteFlags(m.flags),
+		AutocompleteOptionsFlags(m.Options()),
+	)
+}
+
+// PopulateOptions sets every field of opts with an arg tag to the value
+// of the matching parsed argument.
+func PopulateOptions(opts interface{}, arguments map[string]Argument) error {
+	for _, field := range optionFields(opts) {
+		if field.isFlag {
+			continue
+		}
+
+		argument, ok := arguments[field.name]
+		if !ok || argument.Value == nil {
+			continue
+		}
+
+		value := reflect.ValueOf(argument.Value)
+		if !value.Type().AssignableTo(field.value.Type()) {
+			return fmt.Errorf("cannot assign %T value of argument %s to %s field", argument.Value, field.name, field.value.Type())
+		}
+		field.value.Set(value)
+	}
+
+	return nil
+}
+
+// choiceValue is a flag.Value for string flags restricted to a set of choices.
+type choiceValue struct {
+	value   *string
+	choices []string
+}
+
+func (c *choiceValue) Set(s string) error {
+	for _, choice := range c.choices {
+		if s == choice {
+			*c.value = s
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(c.choices, ", "))
+}
+
+func (c *choiceValue) String() string {
+	if c.value == nil {
+		return ""
+	}
+	return *c.value
+}
+
+func (c *choiceValue) Type() string { return "string" }
@@ -0,0 +1,72 @@
+package command
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type testOptions struct {
+	Count int    `flag:"count" default:"1" help:"number of lollipops to eat"`
+	Speed string `arg:"speed,optional" default:"normally" choices:"quickly,normally" help:"how quickly to eat the lollipop"`
+}
+
+type testOptionsCommand struct {
+	OptionsMeta[testOptions]
+
+	executed testOptions
+}
+
+func (c *testOptionsCommand) Synopsis() string            { return "Eats lollipops" }
+func (c *testOptionsCommand) Help() string                { return CommandHelp(c) }
+func (c *testOptionsCommand) Examples() map[string]string { return nil }
+func (c *testOptionsCommand) Run(args []string) int       { return c.RunCommand(c, args) }
+func (c *testOptionsCommand) Execute(ctx context.Context, arguments map[string]Argument) error {
+	c.executed = c.Opts
+	return nil
+}
+
+func TestOptionsMeta(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+		want testOptions
+	}{
+		{name: "defaults", want: testOptions{Count: 1, Speed: "normally"}},
+		{name: "specified", args: []string{"--count", "3", "quickly"}, want: testOptions{Count: 3, Speed: "quickly"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := &testOptionsCommand{OptionsMeta: NewOptionsMeta[testOptions](Meta{Ui: (&Meta{}).basicUi()}, "eat", FlagSetClient)}
+			if code := c.Run(tt.args); code != ExitCodeOK {
+				t.Fatalf("unexpected exit code %d", code)
+			}
+			if !reflect.DeepEqual(c.executed, tt.want) {
+				t.Errorf("options = %+v, want %+v", c.executed, tt.want)
+			}
+		})
+	}
+}
+
+func TestOptionsMetaDefinitions(t *testing.T) {
+	t.Parallel()
+
+	c := &testOptionsCommand{OptionsMeta: NewOptionsMeta[testOptions](Meta{}, "eat", FlagSetClient)}
+	if c.Name() != "eat" {
+		t.Errorf("name = %q, want %q", c.Name(), "eat")
+	}
+	if fl := c.FlagSet().Lookup("count"); fl == nil || fl.DefValue != "1" {
+		t.Errorf("count flag = %+v, want a flag defaulting to 1", fl)
+	}
+	if arguments := c.Arguments(); len(arguments) != 1 || arguments[0].Type != ArgumentEnum {
+		t.Errorf("arguments = %+v, want a single enum argument", arguments)
+	}
+	if _, ok := c.AutocompleteFlags()["--count"]; !ok {
+		t.Errorf("completions do not include --count")
+	}
+}
@@ -0,0 +1,168 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/posener/complete"
+	flag "github.com/spf13/pflag"
+)
+
+// ProfileCommand selects and inspects the named profiles defined in the
+// config files of the cli tool. It requires config files to be enabled via
+// App.Config. The profile selected by the use action is written to the
+// config file used by ConfigCommand.
+type ProfileCommand struct {
+	Meta
+}
+
+func (c *ProfileCommand) Help() string {
+	return CommandHelp(c)
+}
+
+func (c *ProfileCommand) Arguments() []Argument {
+	args := []Argument{}
+	args = append(args, Argument{
+		Name:        "action",
+		Description: "the action to perform",
+		Optional:    false,
+		Type:        ArgumentEnum,
+		Choices:     []string{"use", "list", "show"},
+	})
+	args = append(args, Argument{
+		Name:        "name",
+		Description: "the profile to use or show, defaulting to the active profile for show",
+		Optional:    true,
+		Type:        ArgumentString,
+	})
+	return args
+}
+
+func (c *ProfileCommand) AutocompleteFlags() complete.Flags {
+	return MergeAutocompleteFlags(
+		c.Meta.AutocompleteFlags(FlagSetClient),
+		complete.Flags{},
+	)
+}
+
+func (c *ProfileCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictOr(
+		complete.PredictSet("use", "list", "show"),
+		complete.PredictSet(c.Config().Profiles()...),
+	)
+}
+
+func (c *ProfileCommand) Examples() map[string]string {
+	appName := c.AppName()
+	return map[string]string{
+		"List all profiles":                  fmt.Sprintf("%s %s list", appName, c.Name()),
+		"Use the staging profile by default": fmt.Sprintf("%s %s use staging", appName, c.Name()),
+		"Show the settings of a profile":     fmt.Sprintf("%s %s show staging", appName, c.Name()),
+	}
+}
+
+func (c *ProfileCommand) FlagSet() *flag.FlagSet {
+	return c.Meta.FlagSet(c.Name(), FlagSetClient)
+}
+
+func (c *ProfileCommand) Name() string {
+	return "profile"
+}
+
+func (c *ProfileCommand) ParsedArguments(args []string) (map[string]Argument, error) {
+	return c.ParseArguments(args, c.Arguments())
+}
+
+func (c *ProfileCommand) Synopsis() string {
+	return "Select and inspect config profiles"
+}
+
+func (c *ProfileCommand) Run(args []string) int {
+	return c.RunCommand(c, args)
+}
+
+func (c *ProfileCommand) Execute(ctx context.Context, arguments map[string]Argument) error {
+	if c.Config() == nil {
+		return NewInternalError("Config files are not enabled for %s", c.AppName())
+	}
+
+	action := arguments["action"].EnumValue()
+	name := arguments["name"].StringValue()
+	switch action {
+	case "use":
+		if name == "" {
+			return NewUsageError("The use action requires a profile name")
+		}
+		return c.use(name)
+	case "list":
+		if name != "" {
+			return NewUsageError("The list action does not accept a profile name")
+		}
+		return c.list()
+	case "show":
+		if name == "" {
+			name = c.Profile()
+		}
+		if name == "" {
+			return NewUsageError("No profile is active, specify the profile to show")
+		}
+		return c.show(name)
+	}
+
+	return nil
+}
+
+func (c *ProfileCommand) use(name string) error {
+	if c.Config().Profile(name) == nil {
+		return NewNotFoundError("Profile %s does not exist", name)
+	}
+
+	path := c.configPath()
+	values, err := ReadConfigFile(path)
+	if err != nil {
+		return err
+	}
+
+	values["profile"] = name
+	if err := WriteConfigFile(path, values); err != nil {
+		return err
+	}
+
+	c.Ui.Info(fmt.Sprintf("Using profile %s", name))
+	return nil
+}
+
+func (c *ProfileCommand) list() error {
+	lines := []string{}
+	for _, name := range c.Config().Profiles() {
+		if name == c.Profile() {
+			lines = append(lines, "* "+name)
+		} else {
+			lines = append(lines, "  "+name)
+		}
+	}
+
+	if len(lines) > 0 {
+		c.Ui.Output(strings.Join(lines, "\n"))
+	}
+	return nil
+}
+
+func (c *ProfileCommand) show(name string) error {
+	profile := c.Config().Profile(name)
+	if profile == nil {
+		return NewNotFoundError("Profile %s does not exist", name)
+	}
+
+	lines := []string{}
+	for _, key := range profile.Keys() {
+		value, _, _ := profile.Get(key)
+		lines = append(lines, fmt.Sprintf("%s = %s", key, formatConfigValue(value)))
+	}
+
+	if len(lines) > 0 {
+		c.Ui.Output(strings.Join(lines, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/mitchellh/colorstring"
+)
+
+const (
+	// progressRefreshInterval is how often progress is redrawn on a
+	// terminal
+	progressRefreshInterval = 100 * time.Millisecond
+
+	// progressBarWidth is the widest a progress bar is drawn
+	progressBarWidth = 40
+)
+
+// ProgressLogInterval is how often progress is logged through the Ui when
+// stderr is not a terminal.
+var ProgressLogInterval = 10 * time.Second
+
+// spinnerFrames are the frames of the spinner animation
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// Progress reports the progress of a long running operation. On a terminal
+// it is drawn in place on stderr, either as a progress bar when the total
+// amount of work is known or as a spinner otherwise. When stderr is not a
+// terminal, progress is logged through the Ui every ProgressLogInterval
+// instead.
+//
+// Progress is stopped by Done, Stop or the cancellation of Meta.Context,
+// whichever happens first:
+//
+//	bar := c.NewProgressBar("Eating lollipops", int64(len(lollipops)))
+//	defer bar.Stop()
+//	for _, lollipop := range lollipops {
+//		eat(lollipop)
+//		bar.Add(1)
+//	}
+//	bar.Done()
+type Progress struct {
+	mu       sync.Mutex
+	message  string
+	total    int64
+	current  int64
+	started  time.Time
+	frame    int
+	drawn    bool
+	stopped  bool
+	ui       cli.Ui
+	out      io.Writer
+	tty      bool
+	colorize *colorstring.Colorize
+
+	done     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewProgressBar starts reporting progress towards total units of work
+func (m *Meta) NewProgressBar(message string, total int64) *Progress {
+	return m.newProgress(message, total)
+}
+
+// NewSpinner starts reporting progress of work of an unknown size
+func (m *Meta) NewSpinner(message string) *Progress {
+	return m.newProgress(message, 0)
+}
+
+func (m *Meta) newProgress(message string, total int64) *Progress {
+	p := &Progress{
+		message:  message,
+		total:    total,
+		started:  time.Now(),
+		ui:       m.Ui,
+		out:      m.Stderr(),
+		tty:      m.isStderrTerminal(),
+		colorize: m.Colorize(),
+		done:     make(chan struct{}),
+	}
+
+	ctx := m.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	p.wg.Add(1)
+	go p.run(ctx)
+	return p
+}
+
+// Add records n more units of work as complete
+func (p *Progress) Add(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current += n
+}
+
+// Set records current units of work as complete
+func (p *Progress) Set(current int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current = current
+}
+
+// SetMessage replaces the message describing the work
+func (p *Progress) SetMessage(message string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.message = message
+}
+
+// Done stops reporting progress and reports the work as complete
+func (p *Progress) Done() {
+	if !p.stop() {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.total > 0 {
+		p.current = p.total
+	}
+
+	elapsed := time.Since(p.started).Round(time.Second)
+	if p.tty {
+		p.draw(fmt.Sprintf("done in %s", elapsed))
+		fmt.Fprintln(p.out)
+		return
+	}
+	p.ui.Info(fmt.Sprintf("%s: done in %s", p.message, elapsed))
+}
+
+// Stop stops reporting progress without reporting the work as complete,
+// clearing the progress from the terminal. It is safe to call Stop after
+// Done, so it may be deferred.
+func (p *Progress) Stop() {
+	if !p.stop() {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+}
+
+// stop stops the render loop, returning false if it was already stopped
+func (p *Progress) stop() bool {
+	stopped := false
+	p.stopOnce.Do(func() {
+		close(p.done)
+		stopped = true
+	})
+	p.wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return false
+	}
+	p.stopped = stopped
+	return stopped
+}
+
+// run redraws or logs progress until stopped, clearing the progress if
+// ctx is cancelled first
+func (p *Progress) run(ctx context.Context) {
+	defer p.wg.Done()
+
+	interval := ProgressLogInterval
+	if p.tty {
+		interval = progressRefreshInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ctx.Done():
+			p.mu.Lock()
+			p.stopped = true
+			p.clear()
+			p.mu.Unlock()
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			if p.tty {
+				p.frame++
+				p.draw("")
+			} else {
+				p.ui.Info(p.logLine())
+			}
+			p.mu.Unlock()
+		}
+	}
+}
+
+// draw redraws the progress in place, with an optional status in place of
+// the spinner. It must be called with mu held.
+func (p *Progress) draw(status string) {
+	line := p.message
+	if p.total > 0 {
+		percent := p.percent()
+		filled := progressBarWidth * percent / 100
+		bar := "[green]" + strings.Repeat("=", filled) + "[reset]" + strings.Repeat(" ", progressBarWidth-filled)
+		line = fmt.Sprintf("%s [%s] %3d%% (%d/%d)", line, p.colorize.Color(bar), percent, p.current, p.total)
+	} else if status == "" {
+		line = fmt.Sprintf("%s %s (%s)", p.colorize.Color("[cyan]"+spinnerFrames[p.frame%len(spinnerFrames)]), line, time.Since(p.started).Round(time.Second))
+	}
+
+	if status != "" {
+		line = fmt.Sprintf("%s %s", line, status)
+	}
+
+	fmt.Fprint(p.out, "\r\033[K"+line)
+	p.drawn = true
+}
+
+// clear removes the progress from the terminal. It must be called with mu
+// held.
+func (p *Progress) clear() {
+	if p.tty && p.drawn {
+		fmt.Fprint(p.out, "\r\033[K")
+	}
+	p.drawn = false
+}
+
+// logLine returns the line logged when stderr is not a terminal. It must
+// be called with mu held.
+func (p *Progress) logLine() string {
+	elapsed := time.Since(p.started).Round(time.Second)
+	if p.total > 0 {
+		return fmt.Sprintf("%s: %d/%d (%d%%) after %s", p.message, p.current, p.total, p.percent(), elapsed)
+	}
+	return fmt.Sprintf("%s: still running after %s", p.message, elapsed)
+}
+
+// percent returns the percentage of work complete. It must be called with
+// mu held.
+func (p *Progress) percent() int {
+	if p.total <= 0 {
+		return 0
+	}
+
+	percent := int(p.current * 100 / p.total)
+	if percent > 100 {
+		return 100
+	}
+	if percent < 0 {
+		return 0
+	}
+	return percent
+}
@@ -0,0 +1,241 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrNotInteractive is returned by prompts when stdin is not a terminal
+// and no default answer can be assumed.
+var ErrNotInteractive = errors.New("stdin is not a terminal")
+
+// Confirm asks a yes or no question, returning def if no answer is given.
+// If --yes was specified, true is returned without prompting.
+func (m *Meta) Confirm(query string, def bool) (bool, error) {
+	if m.assumeYes {
+		return true, nil
+	}
+
+	if !m.interactive() {
+		return false, notInteractiveError(query)
+	}
+
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+
+	for {
+		answer, err := m.ask(fmt.Sprintf("%s [%s]:", query, hint), false)
+		if err != nil {
+			return false, err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		m.Ui.Error("Please answer yes or no")
+	}
+}
+
+// Select asks the user to choose one of options, by number or by value,
+// returning def if no answer is given. If --yes was specified, def is
+// returned without prompting.
+func (m *Meta) Select(query string, options []string, def string) (string, error) {
+	selected, err := m.selectOptions(query, options, []string{def}, false)
+	if err != nil {
+		return "", err
+	}
+	return selected[0], nil
+}
+
+// MultiSelect asks the user to choose any number of options as a comma
+// separated list of numbers or values, returning defs if no answer is
+// given. If --yes was specified, defs is returned without prompting.
+func (m *Meta) MultiSelect(query string, options []string, defs []string) ([]string, error) {
+	return m.selectOptions(query, options, defs, true)
+}
+
+// selectOptions implements Select and MultiSelect
+func (m *Meta) selectOptions(query string, options []string, defs []string, multiple bool) ([]string, error) {
+	defaults := []string{}
+	for _, def := range defs {
+		if def != "" {
+			defaults = append(defaults, def)
+		}
+	}
+
+	if m.assumeYes && (multiple || len(defaults) > 0) {
+		return defaults, nil
+	}
+
+	if !m.interactive() {
+		return nil, notInteractiveError(query)
+	}
+
+	lines := make([]string, len(options))
+	for i, option := range options {
+		lines[i] = fmt.Sprintf("  %d) %s", i+1, option)
+	}
+	m.Ui.Output(query)
+	m.Ui.Output(strings.Join(lines, "\n"))
+
+	prompt := "Enter a number"
+	if multiple {
+		prompt = "Enter numbers separated by commas"
+	}
+	if len(defaults) > 0 {
+		prompt = fmt.Sprintf("%s [%s]", prompt, strings.Join(defaults, ","))
+	}
+
+	for {
+		answer, err := m.ask(prompt+":", false)
+		if err != nil {
+			return nil, err
+		}
+
+		answer = strings.TrimSpace(answer)
+		if answer == "" && (multiple || len(defaults) > 0) {
+			return defaults, nil
+		}
+
+		selected, err := parseSelection(answer, options, multiple)
+		if err == nil {
+			return selected, nil
+		}
+		m.Ui.Error(err.Error())
+	}
+}
+
+// parseSelection converts a select answer into the options it refers to
+func parseSelection(answer string, options []string, multiple bool) ([]string, error) {
+	parts := []string{answer}
+	if multiple {
+		parts = strings.Split(answer, ",")
+	}
+
+	selected := []string{}
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		found := false
+		if i, err := strconv.Atoi(part); err == nil && i >= 1 && i <= len(options) {
+			selected = append(selected, options[i-1])
+			found = true
+		}
+		for _, option := range options {
+			if !found && part == option {
+				selected = append(selected, option)
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("Invalid selection %q, must be between 1 and %d", part, len(options))
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("Please select an option")
+	}
+	return selected, nil
+}
+
+// Input asks for a line of text, returning def if no answer is given. The
+// answer is checked with validate, if not nil, and the user is asked again
+// until a valid answer is given. If --yes was specified, def is returned
+// without prompting.
+func (m *Meta) Input(query string, def string, validate func(interface{}) error) (string, error) {
+	if m.assumeYes && def != "" {
+		if validate != nil {
+			if err := validate(def); err != nil {
+				return "", err
+			}
+		}
+		return def, nil
+	}
+
+	if !m.interactive() {
+		return "", notInteractiveError(query)
+	}
+
+	prompt := query + ":"
+	if def != "" {
+		prompt = fmt.Sprintf("%s [%s]:", query, def)
+	}
+
+	for {
+		answer, err := m.ask(prompt, false)
+		if err != nil {
+			return "", err
+		}
+
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			answer = def
+		}
+
+		if validate != nil {
+			if err := validate(answer); err != nil {
+				m.Ui.Error(err.Error())
+				continue
+			}
+		}
+		return answer, nil
+	}
+}
+
+// Secret asks for a line of text without echoing it, such as a password.
+// The answer is checked with validate, if not nil, and the user is asked
+// again until a valid answer is given. Secrets cannot be assumed, so
+// --yes has no effect.
+func (m *Meta) Secret(query string, validate func(interface{}) error) (string, error) {
+	if !m.interactive() {
+		return "", notInteractiveError(query)
+	}
+
+	for {
+		answer, err := m.ask(query+":", true)
+		if err != nil {
+			return "", err
+		}
+
+		if validate != nil {
+			if err := validate(answer); err != nil {
+				m.Ui.Error(err.Error())
+				continue
+			}
+		}
+		return answer, nil
+	}
+}
+
+// ask reads an answer from the Ui. Secrets are only read without echoing
+// when reading from the stdin of the process, as the Ui would otherwise
+// read them from the terminal instead of App.Stdin.
+func (m *Meta) ask(query string, secret bool) (string, error) {
+	if secret && m.stdin == nil {
+		return m.Ui.AskSecret(query)
+	}
+	return m.Ui.Ask(query)
+}
+
+// interactive returns whether the user can be prompted
+func (m *Meta) interactive() bool {
+	return m.isStdinTerminal()
+}
+
+// notInteractiveError returns the error for a prompt that cannot be shown
+func notInteractiveError(query string) error {
+	return &UsageError{Err: fmt.Errorf("Unable to prompt %q as %w, specify the answer with flags or --yes instead", query, ErrNotInteractive)}
+}
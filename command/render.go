@@ -0,0 +1,313 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats supported by the --format flag added by FlagSetFormat.
+// Any other value containing "{{" is rendered as a Go text template, with
+// the sprig template functions available.
+const (
+	FormatHuman = "human"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatTable = "table"
+)
+
+// formatValue is a flag.Value for the --format flag, accepting one of the
+// named formats or a template
+type formatValue struct {
+	value *string
+}
+
+func (f *formatValue) Set(s string) error {
+	switch s {
+	case FormatHuman, FormatJSON, FormatYAML, FormatTable:
+		*f.value = s
+		return nil
+	}
+
+	if !strings.Contains(s, "{{") {
+		return fmt.Errorf("must be one of %s, %s, %s, %s or a template", FormatHuman, FormatJSON, FormatYAML, FormatTable)
+	}
+
+	if _, err := parseFormatTemplate(s); err != nil {
+		return err
+	}
+	*f.value = s
+	return nil
+}
+
+func (f *formatValue) String() string {
+	if f.value == nil {
+		return ""
+	}
+	return *f.value
+}
+
+func (f *formatValue) Type() string { return "string" }
+
+// Format returns the output format selected by the --format flag, or
+// FormatHuman if there is none.
+func (m *Meta) Format() string {
+	if m.format == "" {
+		return FormatHuman
+	}
+	return m.format
+}
+
+// Render writes value in the output format selected by the --format flag.
+// The human format is written to the Ui, while the other formats are
+// written to stdout as is, so that they remain parseable when the Ui
+// decorates its output, such as ZerologUiWithFields does:
+//
+//   - human: the String() of fmt.Stringer values, an aligned table for
+//     slices, structs and maps, and the value as is otherwise
+//   - json: the value encoded as indented JSON
+//   - yaml: the value encoded as YAML
+//   - table: an aligned table, with a row for every element of a slice
+//     and a column for every field of a struct or key of a map
+//   - any template: the value rendered by the Go text template
+func (m *Meta) Render(value interface{}) error {
+	output, err := m.renderFormat(m.Format(), value)
+	if err != nil {
+		return NewInternalError("Unable to render output: %s", err.Error())
+	}
+
+	if m.Format() == FormatHuman {
+		m.Ui.Output(output)
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(m.Stdout(), output); err != nil {
+		return NewInternalError("Unable to write output: %s", err.Error())
+	}
+	return nil
+}
+
+// renderFormat renders value in format
+func (m *Meta) renderFormat(format string, value interface{}) (string, error) {
+	switch format {
+	case FormatHuman:
+		if s, ok := value.(fmt.Stringer); ok {
+			return s.String(), nil
+		}
+		if headers, rows, ok := tableRows(value); ok {
+			return m.renderTable(headers, rows), nil
+		}
+		return fmt.Sprint(value), nil
+	case FormatJSON:
+		data, err := json.MarshalIndent(value, "", "  ")
+		return string(data), err
+	case FormatYAML:
+		data, err := yaml.Marshal(value)
+		return strings.TrimSuffix(string(data), "\n"), err
+	case FormatTable:
+		headers, rows, ok := tableRows(value)
+		if !ok {
+			headers, rows = []string{"VALUE"}, [][]string{{formatCell(reflect.ValueOf(value))}}
+		}
+		return m.renderTable(headers, rows), nil
+	}
+
+	tmpl, err := parseFormatTemplate(format)
+	if err != nil {
+		return "", err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := tmpl.Execute(buf, value); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// parseFormatTemplate parses a --format template
+func parseFormatTemplate(format string) (*template.Template, error) {
+	return template.New("format").Funcs(sprig.TxtFuncMap()).Parse(format)
+}
+
+// renderTable renders headers and rows with a Table
+func (m *Meta) renderTable(headers []string, rows [][]string) string {
+	t := m.NewTable(headers...)
+	t.Rows = rows
+	return t.String()
+}
+
+// tableRows converts value into the headers and rows of a table. Slices
+// have a row for every element, with a column for every exported field of
+// struct elements or key of map elements. A struct is rendered as a single
+// row, and a map as a row for every key with KEY and VALUE columns. It
+// returns false if value cannot be rendered as a table.
+func tableRows(value interface{}) ([]string, [][]string, bool) {
+	v := indirectValue(reflect.ValueOf(value))
+	if !v.IsValid() {
+		return nil, nil, false
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		headers, fields := structColumns(v.Type())
+		return headers, [][]string{structRow(v, fields)}, true
+	case reflect.Map:
+		keys := sortedMapKeys(v)
+		rows := make([][]string, 0, len(keys))
+		for _, key := range keys {
+			rows = append(rows, []string{formatCell(key), formatCell(v.MapIndex(key))})
+		}
+		return []string{"KEY", "VALUE"}, rows, true
+	case reflect.Slice, reflect.Array:
+	default:
+		return nil, nil, false
+	}
+
+	elemType := v.Type().Elem()
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	rows := [][]string{}
+	switch elemType.Kind() {
+	case reflect.Struct:
+		headers, fields := structColumns(elemType)
+		for i := 0; i < v.Len(); i++ {
+			elem := indirectValue(v.Index(i))
+			if !elem.IsValid() {
+				rows = append(rows, make([]string, len(headers)))
+				continue
+			}
+			rows = append(rows, structRow(elem, fields))
+		}
+		return headers, rows, true
+	case reflect.Map:
+		columns := map[string]bool{}
+		for i := 0; i < v.Len(); i++ {
+			elem := indirectValue(v.Index(i))
+			if !elem.IsValid() {
+				continue
+			}
+			for _, key := range elem.MapKeys() {
+				columns[formatCell(key)] = true
+			}
+		}
+
+		keys := make([]string, 0, len(columns))
+		for key := range columns {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		headers := make([]string, len(keys))
+		for i, key := range keys {
+			headers[i] = strings.ToUpper(key)
+		}
+
+		for i := 0; i < v.Len(); i++ {
+			elem := indirectValue(v.Index(i))
+			row := make([]string, len(keys))
+			if elem.IsValid() {
+				for _, key := range elem.MapKeys() {
+					column := sort.SearchStrings(keys, formatCell(key))
+					row[column] = formatCell(elem.MapIndex(key))
+				}
+			}
+			rows = append(rows, row)
+		}
+		return headers, rows, true
+	}
+
+	for i := 0; i < v.Len(); i++ {
+		rows = append(rows, []string{formatCell(v.Index(i))})
+	}
+	return []string{"VALUE"}, rows, true
+}
+
+// structColumns returns the headers and field indexes of the exported
+// fields of t. Headers are taken from the json tag of the field if set.
+func structColumns(t reflect.Type) ([]string, []int) {
+	headers := []string{}
+	fields := []int{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+
+		headers = append(headers, strings.ToUpper(name))
+		fields = append(fields, i)
+	}
+	return headers, fields
+}
+
+// structRow returns the cells of the fields of v
+func structRow(v reflect.Value, fields []int) []string {
+	row := make([]string, len(fields))
+	for i, field := range fields {
+		row[i] = formatCell(v.Field(field))
+	}
+	return row
+}
+
+// sortedMapKeys returns the keys of the map v, sorted by their formatted
+// value
+func sortedMapKeys(v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return formatCell(keys[i]) < formatCell(keys[j])
+	})
+	return keys
+}
+
+// formatCell renders a value as a table cell, joining lists with commas
+func formatCell(v reflect.Value) string {
+	v = indirectValue(v)
+	if !v.IsValid() || !v.CanInterface() {
+		return ""
+	}
+
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return fmt.Sprint(v.Interface())
+		}
+		values := make([]string, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			values[i] = formatCell(v.Index(i))
+		}
+		return strings.Join(values, ",")
+	}
+
+	return fmt.Sprint(v.Interface())
+}
+
+// indirectValue dereferences pointers and interfaces, returning an invalid
+// value for nil
+func indirectValue(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
@@ -0,0 +1,56 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestRenderBypassesUi(t *testing.T) {
+	t.Parallel()
+
+	value := map[string]int{"count": 2}
+	tests := []struct {
+		name   string
+		format string
+		stdout string
+		ui     string
+	}{
+		{name: "human", format: FormatHuman, ui: "log: KEY\tVALUE\ncount\t2\n"},
+		{name: "json", format: FormatJSON, stdout: "{\n  \"count\": 2\n}\n"},
+		{name: "yaml", format: FormatYAML, stdout: "count: 2\n"},
+		{name: "template", format: "{{ .count }}", stdout: "2\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			stdout := new(bytes.Buffer)
+			uiOutput := new(bytes.Buffer)
+			meta := &Meta{
+				Ui: &cli.PrefixedUi{
+					OutputPrefix: "log: ",
+					Ui:           &cli.BasicUi{Writer: uiOutput, ErrorWriter: uiOutput},
+				},
+				stdout: stdout,
+				format: tt.format,
+			}
+			if err := meta.Render(value); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if stdout.String() != tt.stdout {
+				t.Errorf("stdout = %q, want %q", stdout.String(), tt.stdout)
+			}
+			if uiOutput.String() != tt.ui {
+				t.Errorf("ui output = %q, want %q", uiOutput.String(), tt.ui)
+			}
+			if tt.format == FormatJSON && !json.Valid(stdout.Bytes()) {
+				t.Errorf("stdout is not valid JSON: %q", stdout.String())
+			}
+		})
+	}
+}
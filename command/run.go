@@ -4,68 +4,95 @@ import (
 	"context"
 	"os"
 
-	colorable "github.com/mattn/go-colorable"
 	"github.com/mitchellh/cli"
-	"golang.org/x/crypto/ssh/terminal"
 )
 
+// SetupRun creates the Meta shared by all commands. The returned Meta's
+// Context is cancelled when SIGINT or SIGTERM is received; callers should
+// stop the SignalHandler once the command has returned and pass the
+// command's exit code through SignalHandler().ExitCode().
+//
+// SetupRun also exports the CLI_APP_NAME and CLI_VERSION env vars for
+// commands that still read them.
+//
+// Deprecated: use App, which performs this setup and runs the command.
 func SetupRun(ctx context.Context, appName string, version string, args []string) *Meta {
 	// Parse flags into env vars for global use
 	SetupEnv(args)
 
+	metaPtr := setupMeta(ctx, &App{Name: appName, Version: version}, args)
+	metaPtr.ExportLegacyEnv()
+	return metaPtr
+}
+
+func setupMeta(ctx context.Context, a *App, args []string) *Meta {
 	// Create the meta object
-	metaPtr := new(Meta)
+	metaPtr := &Meta{
+		appName:  a.Name,
+		version:  a.Version,
+		stdin:    a.Stdin,
+		stdout:   a.Stdout,
+		stderr:   a.Stderr,
+		getenv:   a.Getenv,
+		terminal: a.Terminal,
+	}
 
-	// Don't use color if disabled
-	color := true
-	if os.Getenv(EnvCLINoColor) != "" {
-		color = false
+	// Prompts read from stdin a line at a time, so that the Ui does not
+	// buffer the answers to later prompts
+	if _, ok := metaPtr.stdin.(*os.File); metaPtr.stdin != nil && !ok {
+		metaPtr.stdin = newLineReader(metaPtr.stdin)
 	}
 
-	isTerminal := terminal.IsTerminal(int(os.Stdout.Fd()))
+	// Don't use color if disabled
+	metaPtr.noColor = hasNoColorFlag(args)
+	color := !metaPtr.noColor && metaPtr.Getenv(EnvCLINoColor) == ""
+
 	// Only use colored UI if stdout is a tty, and not disabled
-	if isTerminal && color {
+	if metaPtr.isTerminal() && color {
 		metaPtr.Ui = &cli.ConcurrentUi{
 			Ui: &cli.ColoredUi{
 				ErrorColor: cli.UiColorRed,
 				WarnColor:  cli.UiColorYellow,
 				InfoColor:  cli.UiColorGreen,
-				Ui: &cli.BasicUi{
-					Reader:      os.Stdin,
-					Writer:      colorable.NewColorableStdout(),
-					ErrorWriter: colorable.NewColorableStderr(),
-				},
+				Ui:         metaPtr.basicUi(),
 			},
 		}
 	} else {
 		metaPtr.Ui = &cli.ConcurrentUi{
-			Ui: &cli.BasicUi{
-				Reader:      os.Stdin,
-				Writer:      colorable.NewColorableStdout(),
-				ErrorWriter: colorable.NewColorableStderr(),
-			},
+			Ui: metaPtr.basicUi(),
 		}
 	}
 
-	os.Setenv("CLI_APP_NAME", appName)
-	os.Setenv("CLI_VERSION", version)
-	metaPtr.Context = ctx
+	if a.DisableSignalHandler {
+		metaPtr.Context = ctx
+		return metaPtr
+	}
+
+	gracePeriod := a.ShutdownGracePeriod
+	if gracePeriod == 0 {
+		gracePeriod = DefaultShutdownGracePeriod
+	}
+
+	// Cancel the context on SIGINT/SIGTERM so commands can shut down cleanly
+	metaPtr.Context, metaPtr.signals = NewSignalHandler(ctx, gracePeriod)
 	return metaPtr
 }
 
 // setupEnv parses args and may replace them and sets some env vars to known
 // values based on format options
 func SetupEnv(args []string) {
-	noColor := false
+	// Put back into the env for later
+	if hasNoColorFlag(args) {
+		os.Setenv(EnvCLINoColor, "true")
+	}
+}
+
+// hasNoColorFlag returns whether args contain the --no-color flag
+func hasNoColorFlag(args []string) bool {
 	for _, arg := range args {
-		// Check if color is set
 		if arg == "-no-color" || arg == "--no-color" {
-			noColor = true
+			return true
 		}
 	}
-
-	// Put back into the env for later
-	if noColor {
-		os.Setenv(EnvCLINoColor, "true")
-	}
+	return false
 }
@@ -0,0 +1,146 @@
+package command
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// ExitCodeInterrupt is the conventional exit code for a process
+	// terminated by SIGINT (128 + 2).
+	ExitCodeInterrupt = 130
+
+	// ExitCodeTerminate is the conventional exit code for a process
+	// terminated by SIGTERM (128 + 15).
+	ExitCodeTerminate = 143
+)
+
+// DefaultShutdownGracePeriod is the amount of time commands are given
+// to return after their context has been cancelled by a signal before
+// the process is forcibly exited.
+var DefaultShutdownGracePeriod = 10 * time.Second
+
+// SignalHandler cancels a context when SIGINT or SIGTERM is received.
+//
+// The first signal cancels the context and gives the running command
+// GracePeriod to return. A second signal, or the grace period elapsing,
+// forcibly exits the process with the conventional exit code for the
+// first signal received.
+type SignalHandler struct {
+	// GracePeriod is the amount of time to wait after the first
+	// signal before forcibly exiting. A zero or negative value waits
+	// indefinitely for either the command to return or a second signal,
+	// which App.ShutdownGracePeriod selects with a negative value.
+	GracePeriod time.Duration
+
+	mu       sync.Mutex
+	received os.Signal
+	cancel   context.CancelFunc
+	signals  chan os.Signal
+	done     chan struct{}
+	stopOnce sync.Once
+
+	// exit is called to forcibly exit the process
+	exit func(code int)
+}
+
+// NewSignalHandler returns a context derived from ctx that is cancelled
+// when SIGINT or SIGTERM is received, along with the handler watching
+// for those signals. Stop should be called once the command has returned.
+func NewSignalHandler(ctx context.Context, gracePeriod time.Duration) (context.Context, *SignalHandler) {
+	ctx, cancel := context.WithCancel(ctx)
+	h := &SignalHandler{
+		GracePeriod: gracePeriod,
+		cancel:      cancel,
+		signals:     make(chan os.Signal, 2),
+		done:        make(chan struct{}),
+		exit:        os.Exit,
+	}
+
+	signal.Notify(h.signals, os.Interrupt, syscall.SIGTERM)
+	go h.watch()
+
+	return ctx, h
+}
+
+// Signal returns the first signal received, or nil if no signal has
+// been received.
+func (h *SignalHandler) Signal() os.Signal {
+	if h == nil {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.received
+}
+
+// ExitCode returns the exit code that should be reported for a command
+// that returned code. If a signal was received, the conventional exit
+// code for that signal is returned instead.
+func (h *SignalHandler) ExitCode(code int) int {
+	if signalCode := exitCodeForSignal(h.Signal()); signalCode != 0 {
+		return signalCode
+	}
+
+	return code
+}
+
+// Stop stops watching for signals and releases the derived context.
+func (h *SignalHandler) Stop() {
+	if h == nil {
+		return
+	}
+
+	h.stopOnce.Do(func() {
+		signal.Stop(h.signals)
+		close(h.done)
+		h.cancel()
+	})
+}
+
+func (h *SignalHandler) watch() {
+	var sig os.Signal
+	select {
+	case sig = <-h.signals:
+	case <-h.done:
+		return
+	}
+
+	h.mu.Lock()
+	h.received = sig
+	h.mu.Unlock()
+	h.cancel()
+
+	var timeout <-chan time.Time
+	if h.GracePeriod > 0 {
+		timer := time.NewTimer(h.GracePeriod)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-h.signals:
+		h.exit(exitCodeForSignal(sig))
+	case <-timeout:
+		h.exit(exitCodeForSignal(sig))
+	case <-h.done:
+	}
+}
+
+// exitCodeForSignal returns the conventional exit code for a process
+// terminated by sig, or 0 if sig is nil or not handled.
+func exitCodeForSignal(sig os.Signal) int {
+	switch sig {
+	case os.Interrupt:
+		return ExitCodeInterrupt
+	case syscall.SIGTERM:
+		return ExitCodeTerminate
+	}
+
+	return 0
+}
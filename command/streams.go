@@ -0,0 +1,95 @@
+package command
+
+import (
+	"bufio"
+	"io"
+	"os"
+
+	colorable "github.com/mattn/go-colorable"
+	"github.com/mitchellh/cli"
+)
+
+// Stdin returns the reader commands should read input from, which is the
+// stdin of the process unless overridden by App.Stdin.
+func (m *Meta) Stdin() io.Reader {
+	if m.stdin == nil {
+		return os.Stdin
+	}
+	return m.stdin
+}
+
+// Stdout returns the writer commands should write output to, which is the
+// stdout of the process unless overridden by App.Stdout. Most output
+// should be written through the Ui instead.
+func (m *Meta) Stdout() io.Writer {
+	if m.stdout == nil {
+		return os.Stdout
+	}
+	return m.stdout
+}
+
+// Stderr returns the writer commands should write errors and diagnostics
+// to, which is the stderr of the process unless overridden by App.Stderr.
+// Most errors should be written through the Ui instead.
+func (m *Meta) Stderr() io.Writer {
+	if m.stderr == nil {
+		return os.Stderr
+	}
+	return m.stderr
+}
+
+// Getenv returns the value of the env var key, which is read from the
+// environment of the process unless overridden by App.Getenv.
+func (m *Meta) Getenv(key string) string {
+	if m.getenv == nil {
+		return os.Getenv(key)
+	}
+	return m.getenv(key)
+}
+
+// basicUi returns a Ui reading from and writing to the standard streams
+func (m *Meta) basicUi() *cli.BasicUi {
+	return &cli.BasicUi{
+		Reader:      m.Stdin(),
+		Writer:      colorableWriter(m.Stdout()),
+		ErrorWriter: colorableWriter(m.Stderr()),
+	}
+}
+
+// colorableWriter returns w, wrapped to translate ANSI escape codes for
+// Windows consoles if it is a file
+func colorableWriter(w io.Writer) io.Writer {
+	if f, ok := w.(*os.File); ok {
+		return colorable.NewColorable(f)
+	}
+	return w
+}
+
+// lineReader is a io.Reader that returns at most one line per Read.
+//
+// cli.BasicUi wraps its Reader in a new bufio.Reader for every prompt,
+// which would discard any input buffered past the answer to the first
+// prompt. Reading a line at a time keeps the answers to later prompts in
+// the underlying reader.
+type lineReader struct {
+	r       *bufio.Reader
+	pending []byte
+}
+
+func newLineReader(r io.Reader) *lineReader {
+	return &lineReader{r: bufio.NewReader(r)}
+}
+
+func (l *lineReader) Read(p []byte) (int, error) {
+	if len(l.pending) == 0 {
+		line, err := l.r.ReadBytes('\n')
+		if len(line) == 0 {
+			return 0, err
+		}
+		l.pending = line
+	}
+
+	n := copy(p, l.pending)
+	l.pending = l.pending[n:]
+	return n, nil
+}
@@ -0,0 +1,305 @@
+package command
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mitchellh/cli"
+	"github.com/mitchellh/colorstring"
+)
+
+// Alignment is an enum to define how the cells of a table column are
+// aligned.
+type Alignment uint
+
+const (
+	AlignLeft Alignment = iota
+	AlignRight
+	AlignCenter
+)
+
+// minColumnWidth is the narrowest a column is truncated to when fitting a
+// table to the width of the terminal
+const minColumnWidth = 8
+
+// columnGap is the spacing between table columns
+const columnGap = "   "
+
+// Table renders rows of cells as aligned columns. Cells may contain
+// colorstring markup such as "[red]failed", which is rendered with
+// Meta.Colorize. Tables should be created with Meta.NewTable:
+//
+//	t := c.NewTable("NAME", "COUNT")
+//	t.Align = []command.Alignment{command.AlignLeft, command.AlignRight}
+//	t.AddRow("red", "2")
+//	t.AddRow("[blue]blue", "1")
+//	t.Output()
+type Table struct {
+	Headers []string
+	Rows    [][]string
+
+	// Align holds the alignment of each column, defaulting to AlignLeft
+	Align []Alignment
+
+	// Width is the maximum width of a row. Columns are truncated to fit,
+	// or wrapped onto multiple lines if Wrap is set. Zero disables the
+	// limit.
+	Width int
+	Wrap  bool
+
+	// NoHeaders omits the headers
+	NoHeaders bool
+
+	// TSV renders the table as tab separated values, without alignment,
+	// truncation or colors
+	TSV bool
+
+	ui       cli.Ui
+	colorize *colorstring.Colorize
+}
+
+// NewTable returns a Table with the given headers that writes to the Ui.
+// The table is fit to the width of the terminal, omits the headers if
+// --no-headers was specified, and is rendered as tab separated values if
+// stdout is not a terminal.
+func (m *Meta) NewTable(headers ...string) *Table {
+	return &Table{
+		Headers:   headers,
+		Rows:      [][]string{},
+		Width:     m.terminalWidth(),
+		NoHeaders: m.noHeaders,
+		TSV:       !m.isTerminal(),
+		ui:        m.Ui,
+		colorize:  m.Colorize(),
+	}
+}
+
+// AddRow appends a row of cells to the table
+func (t *Table) AddRow(cells ...string) {
+	t.Rows = append(t.Rows, cells)
+}
+
+// Output writes the table to the Ui of the Meta that created it
+func (t *Table) Output() {
+	if s := t.String(); s != "" {
+		t.ui.Output(s)
+	}
+}
+
+// String renders the table
+func (t *Table) String() string {
+	rows := t.Rows
+	if !t.NoHeaders && len(t.Headers) > 0 {
+		rows = append([][]string{t.Headers}, rows...)
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+
+	columns := 0
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+
+	if t.TSV {
+		lines := make([]string, len(rows))
+		for i, row := range rows {
+			cells := make([]string, len(row))
+			for j, cell := range row {
+				cells[j] = strings.NewReplacer("\t", " ", "\n", " ").Replace(t.plain(cell))
+			}
+			lines[i] = strings.Join(cells, "\t")
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	widths := make([]int, columns)
+	for _, row := range rows {
+		for i, cell := range row {
+			if w := displayWidth(t.plain(cell)); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	widths = fitColumns(widths, t.Width)
+
+	lines := []string{}
+	for _, row := range rows {
+		// Each cell may span multiple lines when wrapped
+		cellLines := make([][]string, columns)
+		height := 1
+		for i := 0; i < columns; i++ {
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+			cellLines[i] = t.fitCell(cell, widths[i])
+			if len(cellLines[i]) > height {
+				height = len(cellLines[i])
+			}
+		}
+
+		for l := 0; l < height; l++ {
+			cells := make([]string, columns)
+			for i := 0; i < columns; i++ {
+				cell := ""
+				if l < len(cellLines[i]) {
+					cell = cellLines[i][l]
+				}
+				cells[i] = t.alignCell(cell, widths[i], t.alignment(i))
+			}
+			lines = append(lines, strings.TrimRight(strings.Join(cells, columnGap), " "))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// alignment returns the alignment of column i
+func (t *Table) alignment(i int) Alignment {
+	if i < len(t.Align) {
+		return t.Align[i]
+	}
+	return AlignLeft
+}
+
+// plain returns cell without color markup
+func (t *Table) plain(cell string) string {
+	c := t.colorizer()
+	plain := &colorstring.Colorize{Colors: c.Colors, Disable: true}
+	return plain.Color(cell)
+}
+
+// colorizer returns the Colorize used to render color markup
+func (t *Table) colorizer() *colorstring.Colorize {
+	if t.colorize == nil {
+		t.colorize = &colorstring.Colorize{Colors: colorstring.DefaultColors, Reset: true}
+	}
+	return t.colorize
+}
+
+// fitCell returns the lines of cell fit to width, truncating or wrapping
+// it as needed. Colors are kept for cells that fit, while cells that are
+// truncated or wrapped keep only their leading color.
+func (t *Table) fitCell(cell string, width int) []string {
+	plain := t.plain(cell)
+	if displayWidth(plain) <= width {
+		return []string{cell}
+	}
+
+	prefix := t.colorizer().ColorPrefix(cell)
+	lines := []string{}
+	if t.Wrap {
+		for _, line := range wrapText(plain, width) {
+			lines = append(lines, prefix+line)
+		}
+		return lines
+	}
+
+	return []string{prefix + truncateText(plain, width)}
+}
+
+// alignCell pads cell to width and renders its color markup
+func (t *Table) alignCell(cell string, width int, align Alignment) string {
+	padding := width - displayWidth(t.plain(cell))
+	if padding < 0 {
+		padding = 0
+	}
+
+	colored := t.colorizer().Color(cell)
+	switch align {
+	case AlignRight:
+		return strings.Repeat(" ", padding) + colored
+	case AlignCenter:
+		left := padding / 2
+		return strings.Repeat(" ", left) + colored + strings.Repeat(" ", padding-left)
+	}
+	return colored + strings.Repeat(" ", padding)
+}
+
+// fitColumns shrinks the widest columns until a row fits within limit,
+// without shrinking any column below minColumnWidth
+func fitColumns(widths []int, limit int) []int {
+	if limit <= 0 {
+		return widths
+	}
+
+	total := len(columnGap) * (len(widths) - 1)
+	for _, w := range widths {
+		total += w
+	}
+
+	for total > limit {
+		widest := 0
+		for i, w := range widths {
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+
+		if widths[widest] <= minColumnWidth {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+
+	return widths
+}
+
+// truncateText shortens s to width columns, ending it with an ellipsis
+func truncateText(s string, width int) string {
+	if displayWidth(s) <= width {
+		return s
+	}
+	if width <= 1 {
+		return "…"
+	}
+
+	truncated := ""
+	for _, r := range s {
+		if displayWidth(truncated+string(r)) > width-1 {
+			break
+		}
+		truncated += string(r)
+	}
+	return truncated + "…"
+}
+
+// wrapText splits s into lines of at most width columns, breaking on
+// whitespace where possible
+func wrapText(s string, width int) []string {
+	lines := []string{}
+	line := ""
+	for _, word := range strings.Fields(s) {
+		for displayWidth(word) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			n := widthIndex(word, width)
+			if n == 0 {
+				_, n = utf8.DecodeRuneInString(word)
+			}
+			lines = append(lines, word[:n])
+			word = word[n:]
+		}
+
+		switch {
+		case line == "":
+			line = word
+		case displayWidth(line)+1+displayWidth(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
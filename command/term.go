@@ -0,0 +1,208 @@
+package command
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Terminal overrides the detection of which of the standard streams of the
+// cli tool are terminals, and of the width of the terminal. Streams are
+// otherwise detected as terminals when they are a file attached to one.
+type Terminal struct {
+	Stdin  bool
+	Stdout bool
+	Stderr bool
+
+	// Width is the width of the terminal in columns, or zero if unknown
+	Width int
+}
+
+// isTerminal returns whether stdout is a terminal
+func (m *Meta) isTerminal() bool {
+	if m.terminal != nil {
+		return m.terminal.Stdout
+	}
+	return isFileTerminal(m.Stdout())
+}
+
+// isStdinTerminal returns whether stdin is a terminal
+func (m *Meta) isStdinTerminal() bool {
+	if m.terminal != nil {
+		return m.terminal.Stdin
+	}
+	return isFileTerminal(m.Stdin())
+}
+
+// isStderrTerminal returns whether stderr is a terminal
+func (m *Meta) isStderrTerminal() bool {
+	if m.terminal != nil {
+		return m.terminal.Stderr
+	}
+	return isFileTerminal(m.Stderr())
+}
+
+// DefaultHelpWidth is the width help output is wrapped to when the width
+// of the terminal is unknown, such as when the output is piped.
+const DefaultHelpWidth = 80
+
+// terminalWidth returns the width of the terminal in columns, taken from
+// the COLUMNS env var if set and otherwise from stdout. It returns 0 if the
+// width is unknown, such as when stdout is not a terminal.
+func (m *Meta) terminalWidth() int {
+	if columns, err := strconv.Atoi(m.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+
+	if m.terminal != nil {
+		return m.terminal.Width
+	}
+	return fileTerminalWidth(m.Stdout())
+}
+
+// HelpWidth returns the width in columns that help output is wrapped to.
+// Help is written to stderr, so this is the width of the terminal taken
+// from the COLUMNS env var if set and otherwise from stderr or stdout,
+// falling back to DefaultHelpWidth when neither is a terminal.
+func (m *Meta) HelpWidth() int {
+	width := m.terminalWidth()
+	if width == 0 && m.terminal == nil {
+		width = fileTerminalWidth(m.Stderr())
+	}
+	if width == 0 {
+		return DefaultHelpWidth
+	}
+	return width
+}
+
+// helpWidthFor returns the width the help of a command is wrapped to.
+// Commands embedding Meta detect it from their own streams and env vars,
+// while the process is consulted for commands that do not.
+func helpWidthFor(cmd interface{}) int {
+	if c, ok := cmd.(interface{ HelpWidth() int }); ok {
+		return c.HelpWidth()
+	}
+	return (&Meta{}).HelpWidth()
+}
+
+// fileTerminalWidth returns the width of the terminal stream is attached
+// to, or 0 if it is not a file attached to a terminal
+func fileTerminalWidth(stream io.Writer) int {
+	f, ok := stream.(*os.File)
+	if !ok || !terminal.IsTerminal(int(f.Fd())) {
+		return 0
+	}
+
+	width, _, err := terminal.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// isFileTerminal returns whether stream is a file attached to a terminal
+func isFileTerminal(stream interface{}) bool {
+	f, ok := stream.(*os.File)
+	return ok && terminal.IsTerminal(int(f.Fd()))
+}
+
+// ansiPrefixPattern matches an ANSI escape sequence, such as a color, at
+// the start of a string
+var ansiPrefixPattern = regexp.MustCompile(`^\x1b\[[0-9;?]*[A-Za-z]`)
+
+// displayWidth returns the number of columns s occupies when printed,
+// ignoring ANSI escape sequences and counting wide characters, such as
+// CJK ideographs, as two columns
+func displayWidth(s string) int {
+	width := 0
+	for i := 0; i < len(s); {
+		if n := ansiPrefixLength(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		width += runeWidth(r)
+		i += size
+	}
+	return width
+}
+
+// widthIndex returns the length in bytes of the longest prefix of s that
+// occupies at most width columns when printed
+func widthIndex(s string, width int) int {
+	w := 0
+	for i := 0; i < len(s); {
+		if n := ansiPrefixLength(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if w+runeWidth(r) > width {
+			return i
+		}
+		w += runeWidth(r)
+		i += size
+	}
+	return len(s)
+}
+
+// ansiPrefixLength returns the length in bytes of the ANSI escape sequence
+// s starts with, or 0 if it does not start with one
+func ansiPrefixLength(s string) int {
+	if s == "" || s[0] != '\x1b' {
+		return 0
+	}
+	if loc := ansiPrefixPattern.FindStringIndex(s); loc != nil {
+		return loc[1]
+	}
+	return 0
+}
+
+// runeWidth returns the number of columns r occupies when printed
+func runeWidth(r rune) int {
+	switch {
+	case r == 0 || unicode.IsControl(r):
+		return 0
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case isWideRune(r):
+		return 2
+	}
+	return 1
+}
+
+// wideRanges are the ranges of characters that are displayed as two columns
+// wide, from the East Asian Wide and Fullwidth categories of Unicode
+var wideRanges = []struct{ from, to rune }{
+	{0x1100, 0x115F},   // Hangul Jamo
+	{0x2E80, 0x303E},   // CJK Radicals to CJK Symbols and Punctuation
+	{0x3041, 0x33FF},   // Hiragana to CJK Compatibility
+	{0x3400, 0x4DBF},   // CJK Unified Ideographs Extension A
+	{0x4E00, 0x9FFF},   // CJK Unified Ideographs
+	{0xA000, 0xA4CF},   // Yi Syllables and Radicals
+	{0xAC00, 0xD7A3},   // Hangul Syllables
+	{0xF900, 0xFAFF},   // CJK Compatibility Ideographs
+	{0xFE30, 0xFE4F},   // CJK Compatibility Forms
+	{0xFF00, 0xFF60},   // Fullwidth Forms
+	{0xFFE0, 0xFFE6},   // Fullwidth Signs
+	{0x1F300, 0x1F64F}, // Miscellaneous Symbols and Pictographs, Emoticons
+	{0x1F900, 0x1F9FF}, // Supplemental Symbols and Pictographs
+	{0x20000, 0x3FFFD}, // CJK Unified Ideographs Extensions B and beyond
+}
+
+// isWideRune returns whether r is displayed as two columns wide
+func isWideRune(r rune) bool {
+	for _, wide := range wideRanges {
+		if r >= wide.from && r <= wide.to {
+			return true
+		}
+	}
+	return false
+}
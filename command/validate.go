@@ -0,0 +1,74 @@
+package command
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ValidateIntRange returns an Argument.Validate function that checks an
+// ArgumentInt value is between min and max, inclusive.
+func ValidateIntRange(min int, max int) func(interface{}) error {
+	return func(value interface{}) error {
+		i, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("expected an int, got %T", value)
+		}
+
+		if i < min || i > max {
+			return fmt.Errorf("must be between %d and %d", min, max)
+		}
+		return nil
+	}
+}
+
+// ValidateFloatRange returns an Argument.Validate function that checks an
+// ArgumentFloat value is between min and max, inclusive.
+func ValidateFloatRange(min float64, max float64) func(interface{}) error {
+	return func(value interface{}) error {
+		f, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("expected a float, got %T", value)
+		}
+
+		if f < min || f > max {
+			return fmt.Errorf("must be between %v and %v", min, max)
+		}
+		return nil
+	}
+}
+
+// ValidateRegexp returns an Argument.Validate function that checks a
+// string value matches pattern. It panics if pattern does not compile.
+func ValidateRegexp(pattern string) func(interface{}) error {
+	re := regexp.MustCompile(pattern)
+	return func(value interface{}) error {
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected a string, got %T", value)
+		}
+
+		if !re.MatchString(s) {
+			return fmt.Errorf("must match %s", pattern)
+		}
+		return nil
+	}
+}
+
+// ValidateChoices returns an Argument.Validate function that checks a
+// string value is one of choices.
+func ValidateChoices(choices ...string) func(interface{}) error {
+	return func(value interface{}) error {
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected a string, got %T", value)
+		}
+
+		for _, choice := range choices {
+			if s == choice {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s", strings.Join(choices, ", "))
+	}
+}
@@ -1,8 +1,8 @@
 package command
 
 import (
+	"context"
 	"fmt"
-	"os"
 
 	"github.com/posener/complete"
 	flag "github.com/spf13/pflag"
@@ -33,7 +33,7 @@ func (c *VersionCommand) AutocompleteArgs() complete.Predictor {
 }
 
 func (c *VersionCommand) Examples() map[string]string {
-	appName := os.Getenv("CLI_APP_NAME")
+	appName := c.AppName()
 	return map[string]string{
 		"Return the version of the binary": fmt.Sprintf("%s %s", appName, c.Name()),
 	}
@@ -48,7 +48,7 @@ func (c *VersionCommand) Name() string {
 }
 
 func (c *VersionCommand) ParsedArguments(args []string) (map[string]Argument, error) {
-	return ParseArguments(args, c.Arguments())
+	return c.ParseArguments(args, c.Arguments())
 }
 
 func (c *VersionCommand) Synopsis() string {
@@ -56,22 +56,10 @@ func (c *VersionCommand) Synopsis() string {
 }
 
 func (c *VersionCommand) Run(args []string) int {
-	flags := c.FlagSet()
-	flags.Usage = func() { c.Ui.Output(c.Help()) }
-	if err := flags.Parse(args); err != nil {
-		c.Ui.Error(err.Error())
-		c.Ui.Error(CommandErrorText(c))
-		return 1
-	}
-
-	_, err := c.ParsedArguments(flags.Args())
-	if err != nil {
-		c.Ui.Error(err.Error())
-		c.Ui.Error(CommandErrorText(c))
-		return 1
-	}
-
-	c.Ui.Output(os.Getenv("CLI_VERSION"))
+	return c.RunCommand(c, args)
+}
 
-	return 0
+func (c *VersionCommand) Execute(ctx context.Context, arguments map[string]Argument) error {
+	c.Ui.Output(c.AppVersion())
+	return nil
 }
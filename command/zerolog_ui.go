@@ -1,6 +1,7 @@
 package command
 
 import (
+	"io"
 	"os"
 
 	"github.com/mitchellh/cli"
@@ -74,11 +75,12 @@ func (u *ZerologUi) Fields(newFields map[string]interface{}) *ZerologUi {
 }
 
 func ZerologUiWithFields(ui cli.Ui, fields map[string]interface{}) *ZerologUi {
+	stdout, stderr := uiWriters(ui)
 	stderrWriter := zerolog.NewConsoleWriter(func(w *zerolog.ConsoleWriter) {
-		w.Out = os.Stderr
+		w.Out = stderr
 	})
 	stdoutWriter := zerolog.NewConsoleWriter(func(w *zerolog.ConsoleWriter) {
-		w.Out = os.Stdout
+		w.Out = stdout
 	})
 	return &ZerologUi{
 		StderrLogger:      zerolog.New(stderrWriter).With().Fields(fields).Timestamp().Logger(),
@@ -90,11 +92,12 @@ func ZerologUiWithFields(ui cli.Ui, fields map[string]interface{}) *ZerologUi {
 }
 
 func HumanZerologUiWithFields(ui cli.Ui, fields map[string]interface{}) *ZerologUi {
+	stdout, stderr := uiWriters(ui)
 	stderrWriter := NewHumanWriter(func(w *HumanWriter) {
-		w.Out = os.Stderr
+		w.Out = stderr
 	})
 	stdoutWriter := NewHumanWriter(func(w *HumanWriter) {
-		w.Out = os.Stdout
+		w.Out = stdout
 	})
 	return &ZerologUi{
 		StderrLogger:      zerolog.New(stderrWriter).With().Fields(fields).Timestamp().Logger(),
@@ -104,3 +107,32 @@ func HumanZerologUiWithFields(ui cli.Ui, fields map[string]interface{}) *Zerolog
 		OutputIndentField: true,
 	}
 }
+
+// uiWriters returns the writers of the BasicUi wrapped by ui, so that logs
+// are written to the streams of the cli tool. It falls back to stdout and
+// stderr for any other Ui.
+func uiWriters(ui cli.Ui) (io.Writer, io.Writer) {
+	for {
+		switch u := ui.(type) {
+		case *cli.BasicUi:
+			stdout, stderr := u.Writer, u.ErrorWriter
+			if stdout == nil {
+				stdout = os.Stdout
+			}
+			if stderr == nil {
+				stderr = os.Stderr
+			}
+			return stdout, stderr
+		case *cli.ConcurrentUi:
+			ui = u.Ui
+		case *cli.ColoredUi:
+			ui = u.Ui
+		case *cli.PrefixedUi:
+			ui = u.Ui
+		case *ZerologUi:
+			ui = u.Ui
+		default:
+			return os.Stdout, os.Stderr
+		}
+	}
+}
@@ -1,8 +1,8 @@
 package commands
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"strconv"
 
 	"github.com/josegonzalez/cli-skeleton/command"
@@ -28,7 +28,7 @@ func (c *GlobalCommand) Help() string {
 }
 
 func (c *GlobalCommand) Examples() map[string]string {
-	appName := os.Getenv("CLI_APP_NAME")
+	appName := c.AppName()
 	return map[string]string{
 		"Prints the values of the global flags": fmt.Sprintf("%s %s", appName, c.Name()),
 	}
@@ -44,41 +44,27 @@ func (c *GlobalCommand) AutocompleteArgs() complete.Predictor {
 }
 
 func (c *GlobalCommand) ParsedArguments(args []string) (map[string]command.Argument, error) {
-	return command.ParseArguments(args, c.Arguments())
+	return c.ParseArguments(args, c.Arguments())
 }
 
 func (c *GlobalCommand) FlagSet() *flag.FlagSet {
 	f := c.Meta.FlagSet(c.Name(), command.FlagSetClient)
-	c.GlobalFlags(f)
 	return f
 }
 
 func (c *GlobalCommand) AutocompleteFlags() complete.Flags {
 	return command.MergeAutocompleteFlags(
 		c.Meta.AutocompleteFlags(command.FlagSetClient),
-		c.AutocompleteGlobalFlags(),
 		complete.Flags{},
 	)
 }
 
 func (c *GlobalCommand) Run(args []string) int {
-	flags := c.FlagSet()
-	flags.Usage = func() { c.Ui.Output(c.Help()) }
-	if err := flags.Parse(args); err != nil {
-		c.Ui.Error(err.Error())
-		c.Ui.Error(command.CommandErrorText(c))
-		return 1
-	}
-
-	_, err := c.ParsedArguments(flags.Args())
-	if err != nil {
-		c.Ui.Error(err.Error())
-		c.Ui.Error(command.CommandErrorText(c))
-		return 1
-	}
+	return c.RunCommand(c, args)
+}
 
+func (c *GlobalCommand) Execute(ctx context.Context, arguments map[string]command.Argument) error {
 	c.Ui.Output(fmt.Sprintf("Global bool value: %s", strconv.FormatBool(c.global)))
 	c.Ui.Output(fmt.Sprintf("Global string value: %s", c.globalValue))
-
-	return 0
+	return nil
 }
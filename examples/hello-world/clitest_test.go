@@ -0,0 +1,263 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/josegonzalez/cli-skeleton/command/clitest"
+)
+
+// editorFromStdin is an editor command that replaces the edited file with
+// the stdin of the cli tool
+const editorFromStdin = `sh -c cat>"$0"`
+
+func TestRunOutput(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		args     []string
+		stdout   string
+		stderr   string
+		exitCode int
+	}{
+		{
+			name:   "success",
+			args:   []string{"eat", "--count", "2", "quickly"},
+			stdout: "Eating 2 normal lollipop(s) quickly\n",
+		},
+		{
+			name:     "invalid argument",
+			args:     []string{"eat", "sideways"},
+			stderr:   "Invalid value for argument speed: must be one of quickly, normally, slowly\nFor additional help try 'hello-world eat --help'\n",
+			exitCode: 1,
+		},
+		{
+			name:     "unknown command",
+			args:     []string{"drink"},
+			exitCode: 127,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := &clitest.Harness{App: App()}
+			result := h.Run(t, tt.args...)
+			if result.Stdout != tt.stdout {
+				t.Errorf("stdout = %q, want %q", result.Stdout, tt.stdout)
+			}
+			if tt.stderr != "" && result.Stderr != tt.stderr {
+				t.Errorf("stderr = %q, want %q", result.Stderr, tt.stderr)
+			}
+			if tt.stderr == "" && tt.exitCode == 0 && result.Stderr != "" {
+				t.Errorf("unexpected stderr: %q", result.Stderr)
+			}
+			if result.ExitCode != tt.exitCode {
+				t.Errorf("exit code = %d, want %d", result.ExitCode, tt.exitCode)
+			}
+		})
+	}
+}
+
+func TestRunEnv(t *testing.T) {
+	t.Parallel()
+
+	h := &clitest.Harness{
+		App: App(),
+		Env: map[string]string{"LOLLIPOP_COLOR": "red"},
+	}
+	result := h.Run(t, "eat")
+	if want := "Eating 1 red lollipop(s) normally\n"; result.Stdout != want {
+		t.Errorf("stdout = %q, want %q", result.Stdout, want)
+	}
+}
+
+func TestRunStdin(t *testing.T) {
+	t.Parallel()
+
+	h := &clitest.Harness{
+		App:   App(),
+		Env:   map[string]string{"EDITOR": editorFromStdin},
+		Stdin: "[eat]\ncount = 4\n",
+	}
+	if result := h.Run(t, "config", "edit"); result.ExitCode != 0 {
+		t.Fatalf("unexpected exit code %d: %s", result.ExitCode, result.Stderr)
+	}
+
+	result := h.Run(t, "eat")
+	if want := "Eating 4 normal lollipop(s) normally\n"; result.Stdout != want {
+		t.Errorf("stdout = %q, want %q", result.Stdout, want)
+	}
+}
+
+func TestRunIsolation(t *testing.T) {
+	t.Parallel()
+
+	for _, count := range []string{"1", "2", "3", "4", "5"} {
+		t.Run(count, func(t *testing.T) {
+			t.Parallel()
+
+			h := &clitest.Harness{
+				App:   App(),
+				Env:   map[string]string{"EDITOR": editorFromStdin},
+				Stdin: "[eat]\ncount = " + count + "\n",
+			}
+			if result := h.Run(t, "config", "edit"); result.ExitCode != 0 {
+				t.Fatalf("unexpected exit code %d: %s", result.ExitCode, result.Stderr)
+			}
+
+			result := h.Run(t, "eat")
+			if want := "Eating " + count + " normal lollipop(s) normally\n"; result.Stdout != want {
+				t.Errorf("stdout = %q, want %q", result.Stdout, want)
+			}
+		})
+	}
+}
+
+func TestRunDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".hello-world.toml"), []byte("[eat]\ncolor = \"green\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	app := App()
+	app.Dir = dir
+	h := &clitest.Harness{App: app}
+	result := h.Run(t, "eat")
+	if want := "Eating 1 green lollipop(s) normally\n"; result.Stdout != want {
+		t.Errorf("stdout = %q, want %q", result.Stdout, want)
+	}
+
+}
+
+func TestDocsIgnoreConfig(t *testing.T) {
+	t.Parallel()
+
+	h := &clitest.Harness{
+		App:   App(),
+		Env:   map[string]string{"EDITOR": editorFromStdin},
+		Stdin: "profile = \"dev\"\n\n[eat]\ncolor = \"red\"\n\n[profiles.dev.eat]\ncount = 5\n",
+	}
+	if result := h.Run(t, "config", "edit"); result.ExitCode != 0 {
+		t.Fatalf("unexpected exit code %d: %s", result.ExitCode, result.Stderr)
+	}
+
+	dir := t.TempDir()
+	if result := h.Run(t, "docs", "--output", dir, "markdown"); result.ExitCode != 0 {
+		t.Fatalf("unexpected exit code %d: %s", result.ExitCode, result.Stderr)
+	}
+
+	page, err := os.ReadFile(filepath.Join(dir, "eat.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, setting := range []string{"`red`", "`dev`", "`5`"} {
+		if strings.Contains(string(page), setting) {
+			t.Errorf("docs contain the setting %s from the config file:\n%s", setting, page)
+		}
+	}
+}
+
+func TestRunBrokenConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "hello-world"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "hello-world", "config.toml"), []byte("broken = [\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		exitCode int
+	}{
+		{name: "help", args: []string{"--help"}, exitCode: 0},
+		{name: "profile", args: []string{"profile", "list"}, exitCode: 0},
+		{name: "command", args: []string{"eat"}, exitCode: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := &clitest.Harness{
+				App: App(),
+				Env: map[string]string{"XDG_CONFIG_HOME": dir},
+			}
+			result := h.Run(t, tt.args...)
+			if result.ExitCode != tt.exitCode {
+				t.Errorf("exit code = %d, want %d: %s", result.ExitCode, tt.exitCode, result.Stderr)
+			}
+			if !strings.Contains(result.Stderr, "Unable to parse config file") {
+				t.Errorf("stderr does not report the broken config file: %q", result.Stderr)
+			}
+		})
+	}
+}
+
+func TestConfigEditBrokenConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hello-world", "config.toml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("broken = [\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := &clitest.Harness{
+		App:   App(),
+		Env:   map[string]string{"XDG_CONFIG_HOME": dir, "EDITOR": editorFromStdin},
+		Stdin: "[eat]\ncount = 3\n",
+	}
+	if result := h.Run(t, "config", "edit"); result.ExitCode != 0 {
+		t.Fatalf("unexpected exit code %d: %s", result.ExitCode, result.Stderr)
+	}
+
+	result := h.Run(t, "eat")
+	if want := "Eating 3 normal lollipop(s) normally\n"; result.Stdout != want {
+		t.Errorf("stdout = %q, want %q", result.Stdout, want)
+	}
+	if result.Stderr != "" {
+		t.Errorf("unexpected stderr: %q", result.Stderr)
+	}
+}
+
+func TestConfigListSources(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hello-world", "config.toml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	config := "color = \"red\"\nprofile = \"dev\"\n\n[eat]\ncount = 2\n\n[profiles.dev]\ncolor = \"blue\"\n"
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := &clitest.Harness{
+		App: App(),
+		Env: map[string]string{"XDG_CONFIG_HOME": dir},
+	}
+	result := h.Run(t, "config", "list")
+	want := strings.Join([]string{
+		"color = blue  # config " + path + " (profile dev)",
+		"eat.count = 2  # config " + path,
+		"profile = dev  # config " + path,
+	}, "\n") + "\n"
+	if result.Stdout != want {
+		t.Errorf("stdout = %q, want %q", result.Stdout, want)
+	}
+}
@@ -2,7 +2,6 @@ package commands
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/josegonzalez/cli-skeleton/command"
 	"github.com/posener/complete"
@@ -24,17 +23,46 @@ func (c *EatCommand) Synopsis() string {
 	return "Eats one or more lollipops"
 }
 
+func (c *EatCommand) Category() string {
+	return "Lollipop commands"
+}
+
+func (c *EatCommand) Description() string {
+	return `Eats the specified number of lollipops of a single color, at the
+specified speed.
+
+The color and count may also be set in the config file of hello-world.`
+}
+
 func (c *EatCommand) Help() string {
 	return command.CommandHelp(c)
 }
 
 func (c *EatCommand) Examples() map[string]string {
-	appName := os.Getenv("CLI_APP_NAME")
-	return map[string]string{
-		"Eats one lollipop quickly":  fmt.Sprintf("%s %s quickly", appName, c.Name()),
-		"Eats one lollipop slowly":   fmt.Sprintf("%s %s slowly", appName, c.Name()),
-		"Eats two lollipops quickly": fmt.Sprintf("%s %s --count 2 quickly", appName, c.Name()),
-		"Eats three red lollipops":   fmt.Sprintf("%s %s --count 3 --color red", appName, c.Name()),
+	return nil
+}
+
+func (c *EatCommand) OrderedExamples() []command.Example {
+	appName := c.AppName()
+	return []command.Example{
+		{
+			Description: "Eats one lollipop quickly",
+			Command:     fmt.Sprintf("%s %s quickly", appName, c.Name()),
+			Output:      "Eating 1 normal lollipop(s) quickly",
+		},
+		{
+			Description: "Eats one lollipop slowly",
+			Command:     fmt.Sprintf("%s %s slowly", appName, c.Name()),
+		},
+		{
+			Description: "Eats two lollipops quickly",
+			Command:     fmt.Sprintf("%s %s --count 2 quickly", appName, c.Name()),
+		},
+		{
+			Description: "Eats three red lollipops",
+			Command:     fmt.Sprintf("%s %s --count 3 --color red", appName, c.Name()),
+			Output:      "Eating 3 red lollipop(s) normally",
+		},
 	}
 }
 
@@ -45,6 +73,8 @@ func (c *EatCommand) Arguments() []command.Argument {
 		Description: "how quickly to eat the lollipop",
 		Optional:    true,
 		Type:        command.ArgumentString,
+		Default:     "normally",
+		Validate:    command.ValidateChoices("quickly", "normally", "slowly"),
 	})
 	return args
 }
@@ -54,13 +84,14 @@ func (c *EatCommand) AutocompleteArgs() complete.Predictor {
 }
 
 func (c *EatCommand) ParsedArguments(args []string) (map[string]command.Argument, error) {
-	return command.ParseArguments(args, c.Arguments())
+	return c.ParseArguments(args, c.Arguments())
 }
 
 func (c *EatCommand) FlagSet() *flag.FlagSet {
 	f := c.Meta.FlagSet(c.Name(), command.FlagSetClient)
 	f.IntVar(&c.count, "count", 1, "number of lollipops to eat")
 	f.StringVar(&c.color, "color", "normal", "the color of the lollipops being eaten")
+	command.SetFlagEnv(f, "color", "LOLLIPOP_COLOR")
 	return f
 }
 
@@ -77,7 +108,7 @@ func (c *EatCommand) AutocompleteFlags() complete.Flags {
 func (c *EatCommand) Run(args []string) int {
 	flags := c.FlagSet()
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
-	if err := flags.Parse(args); err != nil {
+	if err := c.ParseFlags(flags, args); err != nil {
 		c.Ui.Error(err.Error())
 		c.Ui.Error(command.CommandErrorText(c))
 		return 1
@@ -90,12 +121,8 @@ func (c *EatCommand) Run(args []string) int {
 		return 1
 	}
 
-	name := arguments["speed"].StringValue()
-	if name == "" {
-		name = "normally"
-	}
-
-	c.Ui.Output(fmt.Sprintf("Eating %d %v lollipop(s) %v", c.count, c.color, name))
+	speed := arguments["speed"].StringValue()
+	c.Ui.Output(fmt.Sprintf("Eating %d %v lollipop(s) %v", c.count, c.color, speed))
 
 	return 0
 }
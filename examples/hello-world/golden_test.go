@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/josegonzalez/cli-skeleton/command/clitest"
+)
+
+// update lets go test -update rewrite golden files, as clitest only
+// registers -clitest.update itself
+var update = flag.Bool("update", false, "rewrite golden files with the actual output")
+
+func TestHelp(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		args    []string
+		columns string
+	}{
+		{name: "app", args: []string{"--help"}},
+		{name: "eat", args: []string{"eat", "--help"}},
+		{name: "eat-narrow", args: []string{"eat", "--help"}, columns: "40"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := &clitest.Harness{App: App()}
+			if tt.columns != "" {
+				h.Env = map[string]string{"COLUMNS": tt.columns}
+			}
+			h.Run(t, tt.args...).AssertGolden(t, t.Name())
+		})
+	}
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"os"
 
 	"hello-world/commands"
@@ -23,26 +22,40 @@ func main() {
 
 // Executes the specified subcommand
 func Run(args []string) int {
-	ctx := context.Background()
-	commandMeta := command.SetupRun(ctx, AppName, Version, args)
-	c := cli.NewCLI(AppName, Version)
-	c.Args = os.Args[1:]
-	c.Commands = command.Commands(ctx, commandMeta, Commands)
-	exitCode, err := c.Run()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err.Error())
-		return 1
-	}
+	app := App()
+	return app.Run(context.Background(), args)
+}
 
-	return exitCode
+// Returns the cli tool
+func App() command.App {
+	return command.App{
+		Name:     AppName,
+		Version:  Version,
+		Commands: Commands,
+		Config:   true,
+
+		HiddenCommands: []string{"docs"},
+	}
 }
 
 // Returns a list of implemented commands
 func Commands(ctx context.Context, meta command.Meta) map[string]cli.CommandFactory {
 	return map[string]cli.CommandFactory{
+		"completion": func() (cli.Command, error) {
+			return &command.CompletionCommand{Meta: meta}, nil
+		},
+		"config": func() (cli.Command, error) {
+			return &command.ConfigCommand{Meta: meta}, nil
+		},
+		"docs": func() (cli.Command, error) {
+			return &command.DocsCommand{Meta: meta}, nil
+		},
 		"eat": func() (cli.Command, error) {
 			return &commands.EatCommand{Meta: meta}, nil
 		},
+		"profile": func() (cli.Command, error) {
+			return &command.ProfileCommand{Meta: meta}, nil
+		},
 		"version": func() (cli.Command, error) {
 			return &command.VersionCommand{Meta: meta}, nil
 		},
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/josegonzalez/cli-skeleton/command/clitest"
+)
+
+func TestExamples(t *testing.T) {
+	t.Parallel()
+
+	h := &clitest.Harness{App: App()}
+	h.RunExamples(t, "eat", "version")
+}
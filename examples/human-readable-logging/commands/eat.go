@@ -2,7 +2,6 @@ package commands
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/josegonzalez/cli-skeleton/command"
 	"github.com/posener/complete"
@@ -29,7 +28,7 @@ func (c *EatCommand) Help() string {
 }
 
 func (c *EatCommand) Examples() map[string]string {
-	appName := os.Getenv("CLI_APP_NAME")
+	appName := c.AppName()
 	return map[string]string{
 		"Eats one lollipop quickly":  fmt.Sprintf("%s %s quickly", appName, c.Name()),
 		"Eats one lollipop slowly":   fmt.Sprintf("%s %s slowly", appName, c.Name()),
@@ -54,7 +53,7 @@ func (c *EatCommand) AutocompleteArgs() complete.Predictor {
 }
 
 func (c *EatCommand) ParsedArguments(args []string) (map[string]command.Argument, error) {
-	return command.ParseArguments(args, c.Arguments())
+	return c.ParseArguments(args, c.Arguments())
 }
 
 func (c *EatCommand) FlagSet() *flag.FlagSet {
@@ -77,7 +76,7 @@ func (c *EatCommand) AutocompleteFlags() complete.Flags {
 func (c *EatCommand) Run(args []string) int {
 	flags := c.FlagSet()
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
-	if err := flags.Parse(args); err != nil {
+	if err := c.ParseFlags(flags, args); err != nil {
 		c.Ui.Error(err.Error())
 		c.Ui.Error(command.CommandErrorText(c))
 		return 1
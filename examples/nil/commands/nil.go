@@ -1,8 +1,8 @@
 package commands
 
 import (
+	"context"
 	"fmt"
-	"os"
 
 	"github.com/josegonzalez/cli-skeleton/command"
 	"github.com/posener/complete"
@@ -26,7 +26,7 @@ func (c *NilCommand) Help() string {
 }
 
 func (c *NilCommand) Examples() map[string]string {
-	appName := os.Getenv("CLI_APP_NAME")
+	appName := c.AppName()
 	return map[string]string{
 		"Does nothing": fmt.Sprintf("%s %s", appName, c.Name()),
 	}
@@ -42,7 +42,7 @@ func (c *NilCommand) AutocompleteArgs() complete.Predictor {
 }
 
 func (c *NilCommand) ParsedArguments(args []string) (map[string]command.Argument, error) {
-	return command.ParseArguments(args, c.Arguments())
+	return c.ParseArguments(args, c.Arguments())
 }
 
 func (c *NilCommand) FlagSet() *flag.FlagSet {
@@ -58,20 +58,9 @@ func (c *NilCommand) AutocompleteFlags() complete.Flags {
 }
 
 func (c *NilCommand) Run(args []string) int {
-	flags := c.FlagSet()
-	flags.Usage = func() { c.Ui.Output(c.Help()) }
-	if err := flags.Parse(args); err != nil {
-		c.Ui.Error(err.Error())
-		c.Ui.Error(command.CommandErrorText(c))
-		return 1
-	}
-
-	_, err := c.ParsedArguments(flags.Args())
-	if err != nil {
-		c.Ui.Error(err.Error())
-		c.Ui.Error(command.CommandErrorText(c))
-		return 1
-	}
+	return c.RunCommand(c, args)
+}
 
-	return 0
+func (c *NilCommand) Execute(ctx context.Context, arguments map[string]command.Argument) error {
+	return nil
 }
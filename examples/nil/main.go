@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"os"
 
 	"nil/commands"
@@ -23,18 +22,12 @@ func main() {
 
 // Executes the specified subcommand
 func Run(args []string) int {
-	ctx := context.Background()
-	commandMeta := command.SetupRun(ctx, AppName, Version, args)
-	c := cli.NewCLI(AppName, Version)
-	c.Args = os.Args[1:]
-	c.Commands = command.Commands(ctx, commandMeta, Commands)
-	exitCode, err := c.Run()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err.Error())
-		return 1
+	app := &command.App{
+		Name:     AppName,
+		Version:  Version,
+		Commands: Commands,
 	}
-
-	return exitCode
+	return app.Run(context.Background(), args)
 }
 
 // Returns a list of implemented commands
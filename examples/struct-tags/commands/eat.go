@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/josegonzalez/cli-skeleton/command"
+)
+
+type EatOptions struct {
+	Count int    `flag:"count" default:"1" help:"number of lollipops to eat"`
+	Color string `flag:"color" default:"normal" env:"LOLLIPOP_COLOR" choices:"normal,red,orange,yellow,green,blue,purple" help:"the color of the lollipops being eaten"`
+	Speed string `arg:"speed,optional" default:"normally" choices:"quickly,normally,slowly" help:"how quickly to eat the lollipop"`
+}
+
+type EatCommand struct {
+	command.OptionsMeta[EatOptions]
+}
+
+func NewEatCommand(meta command.Meta) *EatCommand {
+	return &EatCommand{command.NewOptionsMeta[EatOptions](meta, "eat", command.FlagSetClient)}
+}
+
+func (c *EatCommand) Synopsis() string {
+	return "Eats one or more lollipops"
+}
+
+func (c *EatCommand) Help() string {
+	return command.CommandHelp(c)
+}
+
+func (c *EatCommand) Examples() map[string]string {
+	appName := c.AppName()
+	return map[string]string{
+		"Eats one lollipop quickly":  fmt.Sprintf("%s %s quickly", appName, c.Name()),
+		"Eats one lollipop slowly":   fmt.Sprintf("%s %s slowly", appName, c.Name()),
+		"Eats two lollipops quickly": fmt.Sprintf("%s %s --count 2 quickly", appName, c.Name()),
+		"Eats three red lollipops":   fmt.Sprintf("%s %s --count 3 --color red", appName, c.Name()),
+	}
+}
+
+func (c *EatCommand) Run(args []string) int {
+	return c.RunCommand(c, args)
+}
+
+func (c *EatCommand) Execute(ctx context.Context, arguments map[string]command.Argument) error {
+	c.Ui.Output(fmt.Sprintf("Eating %d %v lollipop(s) %v", c.Opts.Count, c.Opts.Color, c.Opts.Speed))
+	return nil
+}
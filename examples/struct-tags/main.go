@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"struct-tags/commands"
+
+	"github.com/josegonzalez/cli-skeleton/command"
+	"github.com/mitchellh/cli"
+)
+
+// The name of the cli tool
+var AppName = "struct-tags"
+
+// Holds the version
+var Version string
+
+func main() {
+	os.Exit(Run(os.Args[1:]))
+}
+
+// Executes the specified subcommand
+func Run(args []string) int {
+	app := &command.App{
+		Name:     AppName,
+		Version:  Version,
+		Commands: Commands,
+	}
+	return app.Run(context.Background(), args)
+}
+
+// Returns a list of implemented commands
+func Commands(ctx context.Context, meta command.Meta) map[string]cli.CommandFactory {
+	return map[string]cli.CommandFactory{
+		"eat": func() (cli.Command, error) {
+			return commands.NewEatCommand(meta), nil
+		},
+		"version": func() (cli.Command, error) {
+			return &command.VersionCommand{Meta: meta}, nil
+		},
+	}
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"os"
 
 	"zerolog-logging/commands"
@@ -23,19 +22,15 @@ func main() {
 
 // Executes the specified subcommand
 func Run(args []string) int {
-	ctx := context.Background()
-	commandMeta := command.SetupRun(ctx, AppName, Version, args)
-	commandMeta.Ui = command.ZerologUiWithFields(commandMeta.Ui, make(map[string]interface{}, 0))
-	c := cli.NewCLI(AppName, Version)
-	c.Args = os.Args[1:]
-	c.Commands = command.Commands(ctx, commandMeta, Commands)
-	exitCode, err := c.Run()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error executing CLI: %s\n", err.Error())
-		return 1
+	app := &command.App{
+		Name:     AppName,
+		Version:  Version,
+		Commands: Commands,
+		Ui: func(ui cli.Ui) cli.Ui {
+			return command.ZerologUiWithFields(ui, make(map[string]interface{}, 0))
+		},
 	}
-
-	return exitCode
+	return app.Run(context.Background(), args)
 }
 
 // Returns a list of implemented commands